@@ -0,0 +1,600 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const (
+	defaultProductName = "invideo.io"
+	productEnv         = "TRUSTPILOT_PRODUCT"
+
+	minStars = 1
+	maxStars = 5
+
+	dateLayout = "2006-01-02"
+
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// productsFlag collects product names from a comma-separated value or from the repeated flag.
+type productsFlag []string
+
+func (p *productsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *productsFlag) Set(value string) error {
+	*p = append(*p, splitProducts(value)...)
+
+	return nil
+}
+
+// config holds the command-line settings shared by all scraped products.
+type config struct {
+	products     []string
+	format       string
+	output       string
+	verifiedOnly bool
+	invitedOnly  bool
+	organicOnly  bool
+	minRating    int
+	maxRating    int
+	since        time.Time
+	until        time.Time
+	keywords     []string
+	domain       string
+	sortOrder    trustpilot.SortOrder
+	statsOnly    bool
+	startPage    int
+	endPage      int
+	maxReviews   int
+	sinceFile    string
+	concurrency  int
+	rps          float64
+	proxy        *url.URL
+	ignoreRobots bool
+	pagination   trustpilot.Pagination
+	maxPages     int
+	pageTimeout  time.Duration
+	logLevel     slog.Level
+	logFormat    string
+	progress     bool
+	pretty       bool
+	gzip         bool
+	utf8BOM      bool
+	db           string
+	dryRun       bool
+	validate     bool
+	selectors    *trustpilot.Selectors
+	webhook      string
+	webhookBatch int
+	noFile       bool
+	serve        string
+	serveTimeout time.Duration
+	cacheDir     string
+	cacheTTL     time.Duration
+	detectLang   bool
+	lang         string
+	diff         bool
+	diffFiles    [2]string
+	diffFormat   string
+	histogram    bool
+	// failOnIncomplete fails the product when some of its pages couldn't be scraped
+	failOnIncomplete bool
+	headers          http.Header
+	cookies          []*http.Cookie
+	rawText          bool
+	includeHTML      bool
+	deadline         time.Duration
+	// productConcurrency is the number of products scraped in parallel
+	productConcurrency int
+	required           *requirement
+	esIndex            string
+	imagesDir          string
+	strict             bool
+	// totalTolerance is the percent the scraped reviews may differ from the advertised total by
+	totalTolerance float64
+	minDelay       time.Duration
+	maxDelay       time.Duration
+	checkpointDir  string
+	resume         bool
+	// validateThreshold is the minimum percent of the reviews having every required field
+	validateThreshold float64
+	// previous is the output of the previous run loaded from sinceFile
+	previous *trustpilot.ProductReviews
+}
+
+// parseConfig parses and validates the command-line flags.
+func parseConfig() (*config, error) {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n\nScrape Trustpilot reviews of products into JSON, CSV or NDJSON files.\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	var products productsFlag
+	flag.Var(&products, "product", "product name as it appears in the Trustpilot URL, e.g. invideo.io (default \""+defaultProductName+"\").\n"+
+		"Accepts a comma-separated list or can be repeated to scrape several products.\n"+
+		"Precedence: -product flag > "+productEnv+" environment variable > default")
+	cfg := &config{}
+	flag.StringVar(&cfg.format, "format", formatJSON, "output format: json, csv, ndjson, xlsx or es-bulk, the Elasticsearch bulk API format")
+	flag.StringVar(&cfg.output, "output", "", "output file path, use "+stdoutOutput+" to write to stdout (default trustpilot_reviews_<product>.<format>)")
+	flag.BoolVar(&cfg.verifiedOnly, "verified-only", false, "keep only the reviews with the verification label")
+	flag.BoolVar(&cfg.invitedOnly, "invited-only", false, "keep only the reviews the company invited the reviewers to write")
+	flag.BoolVar(&cfg.organicOnly, "organic-only", false, "keep only the organic reviews, which the company didn't invite")
+	flag.IntVar(&cfg.minRating, "min-rating", minStars, "keep only the reviews rated with at least this number of stars")
+	flag.IntVar(&cfg.maxRating, "max-rating", maxStars, "keep only the reviews rated with at most this number of stars")
+	since := flag.String("since", "", "keep only the reviews posted on or after this date, in YYYY-MM-DD format")
+	until := flag.String("until", "", "keep only the reviews posted on or before this date, in YYYY-MM-DD format")
+	contains := flag.String("contains", "", "keep only the reviews whose text or title contains any of these comma-separated keywords, case-insensitive")
+	flag.StringVar(&cfg.domain, "domain", trustpilot.DefaultDomain, "Trustpilot host to scrape, e.g. uk.trustpilot.com for region-specific reviews")
+	sortOrder := flag.String("sort", string(trustpilot.SortDateDesc), "order of the reviews: date-desc, date-asc, rating, useful-desc or page, which keeps the order of the pages. Only page is applied to ndjson, which is written as reviews arrive")
+	flag.BoolVar(&cfg.statsOnly, "stats-only", false, "write only the rating statistics without the reviews, in json format")
+	flag.IntVar(&cfg.startPage, "start-page", 1, "first page to scrape")
+	flag.IntVar(&cfg.endPage, "end-page", 0, "last page to scrape, 0 means the last page of the product")
+	flag.IntVar(&cfg.maxReviews, "max-reviews", 0, "stop after collecting this number of reviews, 0 means no limit")
+	flag.StringVar(&cfg.sinceFile, "since-file", "", "previous json output to update incrementally: only newer reviews are scraped and merged into it")
+	flag.IntVar(&cfg.concurrency, "concurrency", 5, "number of pages scraped in parallel, as many connections are kept open for reuse")
+	flag.Float64Var(&cfg.rps, "rps", 2, "maximum number of requests per second, 0 disables the limit")
+	proxy := flag.String("proxy", "", "proxy URL to route the requests through, e.g. http://host:8080 or socks5://host:1080")
+	flag.BoolVar(&cfg.ignoreRobots, "ignore-robots", false, "scrape even if robots.txt of the domain disallows it")
+	pagination := flag.String("pagination", string(trustpilot.PaginationParallel), "pagination mode: parallel scrapes all pages at once, "+
+		"sequential follows the Next link for layouts without the last page link")
+	flag.IntVar(&cfg.maxPages, "max-pages", 1000, "maximum number of pages followed in sequential pagination")
+	flag.DurationVar(&cfg.pageTimeout, "page-timeout", 15*time.Second, "timeout of every page request attempt, 0 disables it")
+	logLevel := flag.String("log-level", "info", "log level: debug, info, warn or error")
+	flag.StringVar(&cfg.logFormat, "log-format", logFormatText, "log format: text or json")
+	flag.BoolVar(&cfg.progress, "progress", false, "show a live progress line on stderr")
+	flag.BoolVar(&cfg.pretty, "pretty", false, "indent the json output to make it readable")
+	flag.BoolVar(&cfg.utf8BOM, "utf8-bom", false, "start the csv or ndjson output with the UTF-8 byte order mark, so Excel detects the encoding")
+	flag.BoolVar(&cfg.gzip, "gzip", false, "compress the output with gzip, adding .gz to the file name")
+	flag.StringVar(&cfg.db, "db", "", "SQLite database file to store the reviews in instead of the file output, re-runs update the stored reviews")
+	flag.BoolVar(&cfg.dryRun, "dry-run", false, "request only the first page and print the number of pages and estimated reviews without scraping")
+	flag.BoolVar(&cfg.validate, "validate", false, "fail when too few scraped reviews have text, date and rating, or no reviews are scraped")
+	flag.Float64Var(&cfg.validateThreshold, "validate-threshold", 90, "minimum percent of the reviews having every required field for -validate")
+	selectorsFile := flag.String("selectors", "", "json or yaml file overriding the CSS selectors of the review cards, to patch a changed page layout")
+	flag.StringVar(&cfg.webhook, "webhook", "", "URL to POST the scraped reviews to as json batches while they're collected")
+	flag.IntVar(&cfg.webhookBatch, "webhook-batch", 50, "number of reviews in every webhook request")
+	flag.BoolVar(&cfg.noFile, "no-file", false, "don't write the output file, useful with -webhook")
+	flag.StringVar(&cfg.serve, "serve", "", "run an HTTP server on this address, e.g. :8080, scraping the reviews on GET /reviews?product=<name>")
+	flag.DurationVar(&cfg.serveTimeout, "serve-timeout", 5*time.Minute, "timeout of every scraping request of the server, 0 disables it")
+	flag.StringVar(&cfg.cacheDir, "cache-dir", "", "directory to cache the page responses in, so repeated runs don't request them again")
+	flag.DurationVar(&cfg.cacheTTL, "cache-ttl", 24*time.Hour, "how long the cached responses are used before revalidating them with ETag or Last-Modified, 0 means forever")
+	flag.BoolVar(&cfg.strict, "strict", false, "fail the product when the scraped reviews differ from the advertised total by more than -total-tolerance, checked for the json, csv, xlsx and es-bulk outputs")
+	flag.Float64Var(&cfg.totalTolerance, "total-tolerance", 5, "percent the scraped reviews, including the filtered ones, may differ from the advertised total by before it's reported")
+	flag.StringVar(&cfg.imagesDir, "download-images", "", "directory to download the images of the reviews into, a subdirectory per review")
+	flag.StringVar(&cfg.esIndex, "es-index", "trustpilot-reviews", "Elasticsearch index of the reviews written with -format es-bulk")
+	flag.DurationVar(&cfg.minDelay, "min-delay", 0, "shortest random delay before every page request, e.g. 500ms, on top of the -rps limit")
+	flag.DurationVar(&cfg.maxDelay, "max-delay", 0, "longest random delay before every page request, defaults to -min-delay")
+	require := flag.String("require", "", "drop the reviews missing any of these comma-separated fields, like text,rating; supported: "+strings.Join(fieldNames(), ", "))
+	flag.IntVar(&cfg.productConcurrency, "product-concurrency", 1, "number of products scraped in parallel, all sharing the -rps limit")
+	flag.DurationVar(&cfg.deadline, "deadline", 0, "maximum total runtime, e.g. 30m, after which the scraping stops and the reviews collected so far are written, 0 disables it")
+	flag.BoolVar(&cfg.includeHTML, "include-html", false, "add the markup of every review card to the output as raw_html, to debug the selectors")
+	flag.BoolVar(&cfg.rawText, "raw", false, "keep the review text, title and author exactly as in the page markup, without trimming and collapsing whitespace")
+	flag.StringVar(&cfg.checkpointDir, "checkpoint-dir", "", "directory to save the scraping progress of every product in, so an interrupted scraping can be resumed with -resume")
+	flag.BoolVar(&cfg.resume, "resume", false, "continue from the checkpoints in -checkpoint-dir, skipping the pages scraped before")
+	noCache := flag.Bool("no-cache", false, "ignore -cache-dir and request every page")
+	flag.BoolVar(&cfg.detectLang, "detect-lang", false, "detect the language of every review and add it to the output")
+	flag.StringVar(&cfg.lang, "lang", "", "keep only the reviews in this language, as an ISO 639-1 code like en, implies -detect-lang")
+	flag.BoolVar(&cfg.diff, "diff", false, "compare two json outputs given as arguments, like -diff old.json new.json, instead of scraping")
+	flag.StringVar(&cfg.diffFormat, "diff-format", diffFormatText, "format of the -diff report: text or json")
+	flag.BoolVar(&cfg.histogram, "histogram", false, "also write the number and percent of the scraped reviews per star rating next to the output")
+	flag.BoolVar(&cfg.failOnIncomplete, "fail-on-incomplete", false, "exit with an error when some pages couldn't be scraped after retries, "+
+		"otherwise the failed pages are only reported in the output")
+	cfg.headers = make(http.Header)
+	flag.Var(headersFlag(cfg.headers), "header", "extra header of the Trustpilot requests as key=value, can be repeated, never sent to the image hosts")
+	cookies := flag.String("cookies", "", "cookies to send, as a Cookie header like \"name=value; other=value\" or a path to a Netscape cookie file")
+	flag.Parse()
+
+	if err := cfg.logLevel.UnmarshalText([]byte(*logLevel)); err != nil {
+		return nil, fmt.Errorf("invalid -log-level %q, expected debug, info, warn or error", *logLevel)
+	}
+
+	if cfg.logFormat != logFormatText && cfg.logFormat != logFormatJSON {
+		return nil, fmt.Errorf("invalid -log-format %q, expected %s or %s", cfg.logFormat, logFormatText, logFormatJSON)
+	}
+
+	if cfg.diff {
+		if flag.NArg() != 2 {
+			return nil, errors.New("-diff expects two json outputs, like -diff old.json new.json")
+		}
+
+		if cfg.diffFormat != diffFormatText && cfg.diffFormat != diffFormatJSON {
+			return nil, fmt.Errorf("unsupported -diff-format %q, expected %s or %s", cfg.diffFormat, diffFormatText, diffFormatJSON)
+		}
+
+		cfg.diffFiles = [2]string{flag.Arg(0), flag.Arg(1)}
+	}
+
+	if err := validateFormat(cfg.format); err != nil {
+		return nil, err
+	}
+
+	if cfg.startPage < 1 || cfg.endPage < 0 || (cfg.endPage > 0 && cfg.startPage > cfg.endPage) {
+		return nil, fmt.Errorf("invalid page range %d-%d", cfg.startPage, cfg.endPage)
+	}
+
+	if cfg.concurrency < 1 {
+		return nil, fmt.Errorf("invalid -concurrency %d, expected a positive number", cfg.concurrency)
+	}
+
+	if cfg.maxReviews < 0 {
+		return nil, fmt.Errorf("invalid -max-reviews %d, expected a non-negative number", cfg.maxReviews)
+	}
+
+	if cfg.validateThreshold < 0 || cfg.validateThreshold > 100 {
+		return nil, fmt.Errorf("invalid -validate-threshold %g, expected a percent from 0 to 100", cfg.validateThreshold)
+	}
+
+	if cfg.utf8BOM && cfg.format != formatCSV && cfg.format != formatNDJSON {
+		return nil, fmt.Errorf("-utf8-bom supports only %s and %s formats", formatCSV, formatNDJSON)
+	}
+
+	if cfg.statsOnly && cfg.format != formatJSON {
+		return nil, fmt.Errorf("-stats-only supports only %s format", formatJSON)
+	}
+
+	var err error
+	if cfg.sortOrder, err = trustpilot.ParseSortOrder(*sortOrder); err != nil {
+		return nil, err
+	}
+
+	if cfg.pagination, err = trustpilot.ParsePagination(*pagination); err != nil {
+		return nil, err
+	}
+
+	if cfg.maxPages < 1 {
+		return nil, fmt.Errorf("invalid -max-pages %d, expected a positive number", cfg.maxPages)
+	}
+
+	cfg.lang = strings.ToLower(strings.TrimSpace(cfg.lang))
+	if cfg.lang != "" {
+		if len(cfg.lang) != 2 {
+			return nil, fmt.Errorf("invalid -lang %q, expected an ISO 639-1 code like en", cfg.lang)
+		}
+
+		cfg.detectLang = true
+	}
+
+	if *cookies != "" {
+		if cfg.cookies, err = parseCookies(*cookies); err != nil {
+			return nil, err
+		}
+	}
+
+	if *noCache {
+		cfg.cacheDir = ""
+	}
+
+	if *selectorsFile != "" {
+		selectors, err := loadSelectors(*selectorsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.selectors = &selectors
+	}
+
+	if *proxy != "" {
+		if cfg.proxy, err = trustpilot.ParseProxyURL(*proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.domain = strings.ToLower(strings.TrimSpace(cfg.domain))
+	if err := trustpilot.ValidateDomain(cfg.domain); err != nil {
+		return nil, err
+	}
+
+	if cfg.invitedOnly && cfg.organicOnly {
+		return nil, errors.New("-invited-only cannot be combined with -organic-only")
+	}
+
+	if cfg.minRating < minStars || cfg.maxRating > maxStars || cfg.minRating > cfg.maxRating {
+		return nil, fmt.Errorf("invalid rating range %d-%d, expected %d <= min-rating <= max-rating <= %d",
+			cfg.minRating, cfg.maxRating, minStars, maxStars)
+	}
+
+	if cfg.since, err = parseDateFlag("since", *since); err != nil {
+		return nil, err
+	}
+
+	if cfg.until, err = parseDateFlag("until", *until); err != nil {
+		return nil, err
+	}
+
+	if !cfg.since.IsZero() && !cfg.until.IsZero() && cfg.since.After(cfg.until) {
+		return nil, fmt.Errorf("-since %s is after -until %s", *since, *until)
+	}
+
+	for _, keyword := range strings.Split(*contains, ",") {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			cfg.keywords = append(cfg.keywords, keyword)
+		}
+	}
+
+	cfg.products = resolveProductNames(products)
+	if len(cfg.products) == 0 {
+		return nil, errors.New("product name must not be empty")
+	}
+
+	// products are used in the output file names, so pasted review URLs are turned into plain names
+	for i, product := range cfg.products {
+		if cfg.products[i], err = trustpilot.NormalizeProduct(product); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.webhook != "" {
+		if webhookURL, err := url.Parse(cfg.webhook); err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") {
+			return nil, fmt.Errorf("invalid -webhook %q, expected an http or https URL", cfg.webhook)
+		}
+	}
+
+	if cfg.webhookBatch < 1 {
+		return nil, fmt.Errorf("invalid -webhook-batch %d, expected a positive number", cfg.webhookBatch)
+	}
+
+	if cfg.noFile && (cfg.sinceFile != "" || cfg.statsOnly) {
+		return nil, errors.New("-no-file cannot be combined with -since-file or -stats-only")
+	}
+
+	if cfg.db != "" && (cfg.sinceFile != "" || cfg.statsOnly) {
+		return nil, errors.New("-db cannot be combined with -since-file or -stats-only")
+	}
+
+	if *require != "" {
+		if cfg.required, err = parseRequirement(*require); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.totalTolerance < 0 {
+		return nil, fmt.Errorf("invalid -total-tolerance %g, expected a non-negative percent", cfg.totalTolerance)
+	}
+
+	if cfg.format == formatESBulk && (cfg.esIndex == "" || cfg.esIndex != strings.ToLower(cfg.esIndex)) {
+		return nil, fmt.Errorf("invalid -es-index %q, expected a lowercase index name", cfg.esIndex)
+	}
+
+	if cfg.minDelay < 0 || cfg.maxDelay < 0 || (cfg.maxDelay > 0 && cfg.maxDelay < cfg.minDelay) {
+		return nil, fmt.Errorf("invalid delay range %s-%s, expected 0 <= min-delay <= max-delay", cfg.minDelay, cfg.maxDelay)
+	}
+
+	if cfg.productConcurrency < 1 {
+		return nil, fmt.Errorf("invalid -product-concurrency %d, expected a positive number", cfg.productConcurrency)
+	}
+
+	// parallel products would interleave their stdout output, and SQLite allows one writing transaction at a time
+	if cfg.productConcurrency > 1 && (cfg.output == stdoutOutput || cfg.db != "") {
+		return nil, errors.New("-product-concurrency cannot be combined with -output - or -db")
+	}
+
+	// the progress line counts the pages of one product at a time
+	if cfg.productConcurrency > 1 && cfg.progress {
+		return nil, errors.New("-product-concurrency cannot be combined with -progress")
+	}
+
+	if cfg.deadline < 0 {
+		return nil, fmt.Errorf("invalid -deadline %s, expected a non-negative duration", cfg.deadline)
+	}
+
+	if cfg.deadline > 0 && cfg.serve != "" {
+		return nil, errors.New("-deadline cannot be combined with -serve, use -serve-timeout to limit the requests")
+	}
+
+	if cfg.resume && cfg.checkpointDir == "" {
+		return nil, errors.New("-resume requires -checkpoint-dir")
+	}
+
+	// the checkpoint keeps the reviews for the output file, which the other outputs don't write at once
+	if cfg.checkpointDir != "" && (cfg.db != "" || cfg.noFile || cfg.serve != "" || cfg.format == formatNDJSON) {
+		return nil, fmt.Errorf("-checkpoint-dir cannot be combined with -db, -no-file, -serve or %s format", formatNDJSON)
+	}
+
+	if cfg.sinceFile != "" && (len(cfg.products) > 1 || cfg.format == formatNDJSON) {
+		return nil, fmt.Errorf("-since-file can be used with a single product and a non-%s format only", formatNDJSON)
+	}
+
+	// every product is written separately, so a single file path would be overwritten by each of them
+	if len(cfg.products) > 1 && cfg.output != "" && cfg.output != stdoutOutput {
+		return nil, errors.New("-output file path can be used with a single product only")
+	}
+
+	return cfg, nil
+}
+
+// newLogger creates the logger configured by the flags. Logs go to stderr, so they never mix with the reviews
+// written to stdout.
+func newLogger(cfg *config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.logLevel}
+	if cfg.logFormat == logFormatJSON {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// scraperOptions translates the command-line settings into the scraper options.
+func scraperOptions(cfg *config) []trustpilot.Option {
+	opts := []trustpilot.Option{
+		trustpilot.WithDomain(cfg.domain),
+		trustpilot.WithSortOrder(cfg.sortOrder),
+		trustpilot.WithPageOrder(cfg.sortOrder == trustpilot.SortPage),
+		trustpilot.WithPageRange(cfg.startPage, cfg.endPage),
+		trustpilot.WithMaxReviews(cfg.maxReviews),
+		trustpilot.WithConcurrency(cfg.concurrency),
+		trustpilot.WithRateLimit(cfg.rps),
+		trustpilot.WithIgnoreRobots(cfg.ignoreRobots),
+		trustpilot.WithPagination(cfg.pagination),
+		trustpilot.WithMaxPages(cfg.maxPages),
+		trustpilot.WithPageTimeout(cfg.pageTimeout),
+		trustpilot.WithRawText(cfg.rawText),
+		trustpilot.WithDelay(cfg.minDelay, cfg.maxDelay),
+		trustpilot.WithRawHTML(cfg.includeHTML),
+		trustpilot.WithLogger(slog.Default()),
+	}
+	if cfg.verifiedOnly {
+		opts = append(opts, trustpilot.WithFilter(func(review *trustpilot.Review) bool {
+			return review.Verified
+		}))
+	}
+
+	if cfg.invitedOnly || cfg.organicOnly {
+		opts = append(opts, trustpilot.WithFilter(func(review *trustpilot.Review) bool {
+			return review.Invited == cfg.invitedOnly
+		}))
+	}
+
+	// the full range keeps everything, including the reviews with an unknown rating
+	if cfg.minRating != minStars || cfg.maxRating != maxStars {
+		opts = append(opts, trustpilot.WithFilter(func(review *trustpilot.Review) bool {
+			return review.Stars >= cfg.minRating && review.Stars <= cfg.maxRating
+		}))
+	}
+
+	// reviews with an unknown date are dropped, as we can't tell whether they are in the range
+	if !cfg.since.IsZero() || !cfg.until.IsZero() {
+		opts = append(opts, trustpilot.WithFilter(func(review *trustpilot.Review) bool {
+			if review.ParsedDate.IsZero() {
+				return false
+			}
+
+			if !cfg.since.IsZero() && review.ParsedDate.Before(cfg.since) {
+				return false
+			}
+
+			// until is inclusive, so the whole day is in the range
+			return cfg.until.IsZero() || review.ParsedDate.Before(cfg.until.AddDate(0, 0, 1))
+		}))
+	}
+
+	if len(cfg.keywords) > 0 {
+		opts = append(opts, trustpilot.WithFilter(func(review *trustpilot.Review) bool {
+			text := strings.ToLower(review.Text)
+			title := strings.ToLower(review.Title)
+			for _, keyword := range cfg.keywords {
+				if strings.Contains(text, keyword) || strings.Contains(title, keyword) {
+					return true
+				}
+			}
+
+			return false
+		}))
+	}
+
+	// without dated previous reviews everything is scraped and merged
+	if cfg.previous != nil {
+		if newest := newestReviewDate(cfg.previous.Reviews); !newest.IsZero() {
+			opts = append(opts, trustpilot.WithNewerThan(newest))
+		}
+	}
+
+	if cfg.proxy != nil {
+		opts = append(opts, trustpilot.WithProxy(cfg.proxy))
+	}
+
+	if cfg.cacheDir != "" {
+		opts = append(opts, trustpilot.WithCache(cfg.cacheDir, cfg.cacheTTL))
+	}
+
+	if cfg.checkpointDir != "" {
+		opts = append(opts, trustpilot.WithCheckpoint(cfg.checkpointDir, cfg.resume))
+	}
+
+	if len(cfg.headers) > 0 {
+		opts = append(opts, trustpilot.WithHeaders(cfg.headers))
+	}
+
+	if len(cfg.cookies) > 0 {
+		opts = append(opts, trustpilot.WithCookies(cfg.cookies))
+	}
+
+	if cfg.detectLang {
+		opts = append(opts, trustpilot.WithLanguageDetection(true))
+	}
+
+	if cfg.lang != "" {
+		opts = append(opts, trustpilot.WithFilter(func(review *trustpilot.Review) bool {
+			return review.Language == cfg.lang
+		}))
+	}
+
+	// the requirement goes after the other filters, so it counts only the reviews dropped because of it
+	if cfg.required != nil {
+		opts = append(opts, trustpilot.WithFilter(cfg.required.keep))
+	}
+
+	if cfg.selectors != nil {
+		opts = append(opts, trustpilot.WithSelectors(*cfg.selectors))
+	}
+
+	if cfg.progress {
+		opts = append(opts, trustpilot.WithProgress(trustpilot.NewTerminalProgress(os.Stderr)))
+	}
+
+	if cfg.productConcurrency > 1 {
+		opts = append(opts, trustpilot.WithProductConcurrency(cfg.productConcurrency))
+	}
+
+	return opts
+}
+
+// parseDateFlag parses the YYYY-MM-DD value of the flag. An empty value gives the zero time.
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s date %q, expected YYYY-MM-DD format", name, value)
+	}
+
+	return date, nil
+}
+
+// resolveProductNames picks the product names with the precedence: flag > env > default.
+func resolveProductNames(flagValue []string) []string {
+	isFlagSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "product" {
+			isFlagSet = true
+		}
+	})
+
+	if isFlagSet {
+		return flagValue
+	}
+
+	if envValue, ok := os.LookupEnv(productEnv); ok {
+		return splitProducts(envValue)
+	}
+
+	return []string{defaultProductName}
+}
+
+// splitProducts splits a comma-separated list of products, dropping empty entries.
+func splitProducts(value string) []string {
+	products := make([]string, 0)
+	for _, product := range strings.Split(value, ",") {
+		product = strings.TrimSpace(product)
+		if product == "" {
+			continue
+		}
+
+		products = append(products, product)
+	}
+
+	return products
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const createReviewsTable = `CREATE TABLE IF NOT EXISTS reviews (
+	id                     TEXT PRIMARY KEY,
+	product                TEXT NOT NULL,
+	text                   TEXT,
+	date                   TEXT,
+	parsed_date            TIMESTAMP,
+	rating                 TEXT,
+	stars                  INTEGER,
+	title                  TEXT,
+	link                   TEXT,
+	author                 TEXT,
+	country                TEXT,
+	author_review_count    INTEGER,
+	verified               BOOLEAN,
+	reply_text             TEXT,
+	reply_date             TEXT,
+	experience_date        TEXT,
+	parsed_experience_date TIMESTAMP,
+	invited                BOOLEAN,
+	useful                 INTEGER,
+	images                 TEXT,
+	language               TEXT,
+	reply_latency_seconds  INTEGER
+)`
+
+// addedReviewColumns are the columns added to the reviews table after its first version. The tables created
+// before are migrated by adding the missing columns, the stored reviews get them on the next upsert.
+var addedReviewColumns = []struct {
+	name       string
+	definition string
+}{
+	{name: "invited", definition: "BOOLEAN"},
+	{name: "useful", definition: "INTEGER"},
+	{name: "images", definition: "TEXT"},
+	{name: "language", definition: "TEXT"},
+	{name: "reply_latency_seconds", definition: "INTEGER"},
+}
+
+// upsertReview inserts the review or updates it when it's already stored, so re-runs don't duplicate reviews.
+const upsertReview = `INSERT INTO reviews (
+	id, product, text, date, parsed_date, rating, stars, title, link, author, country,
+	author_review_count, verified, reply_text, reply_date, experience_date, parsed_experience_date,
+	invited, useful, images, language, reply_latency_seconds
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	product = excluded.product,
+	text = excluded.text,
+	date = excluded.date,
+	parsed_date = excluded.parsed_date,
+	rating = excluded.rating,
+	stars = excluded.stars,
+	title = excluded.title,
+	link = excluded.link,
+	author = excluded.author,
+	country = excluded.country,
+	author_review_count = excluded.author_review_count,
+	verified = excluded.verified,
+	reply_text = excluded.reply_text,
+	reply_date = excluded.reply_date,
+	experience_date = excluded.experience_date,
+	parsed_experience_date = excluded.parsed_experience_date,
+	invited = excluded.invited,
+	useful = excluded.useful,
+	images = excluded.images,
+	language = excluded.language,
+	reply_latency_seconds = excluded.reply_latency_seconds`
+
+// openReviewsDB opens the SQLite database and creates the reviews table, or migrates the one created
+// by an earlier version.
+func openReviewsDB(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, createReviewsTable); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("create reviews table: %w", err)
+	}
+
+	if err := migrateReviewsTable(ctx, db); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("migrate reviews table: %w", err)
+	}
+
+	return db, nil
+}
+
+// migrateReviewsTable adds the columns missing from the reviews table.
+func migrateReviewsTable(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info('reviews')")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range addedReviewColumns {
+		if columns[column.name] {
+			continue
+		}
+
+		if _, err := db.ExecContext(ctx, "ALTER TABLE reviews ADD COLUMN "+column.name+" "+column.definition); err != nil {
+			return fmt.Errorf("add column %s: %w", column.name, err)
+		}
+		slog.Info("Added column to the reviews table", "column", column.name)
+	}
+
+	return nil
+}
+
+// reviewValues returns the values of the upsertReview placeholders. The images are stored as a JSON array,
+// NULL when there are none.
+func reviewValues(productName string, review *trustpilot.Review) ([]interface{}, error) {
+	replyText, replyDate := "", ""
+	if review.Reply != nil {
+		replyText, replyDate = review.Reply.Text, review.Reply.Date
+	}
+
+	var images interface{}
+	if len(review.Images) > 0 {
+		encoded, err := json.Marshal(review.Images)
+		if err != nil {
+			return nil, err
+		}
+		images = string(encoded)
+	}
+
+	return []interface{}{
+		review.Key(), productName, review.Text, review.Date, review.ParsedDate, review.RatingText, review.Stars,
+		review.Title, review.Link, review.Author, review.Country, review.AuthorReviewCount, review.Verified,
+		replyText, replyDate, review.ExperienceDate, review.ParsedExperienceDate,
+		review.Invited, review.Useful, images, review.Language, int64(review.ReplyLatency.Seconds()),
+	}, nil
+}
+
+// storeProduct scrapes the product reviews and upserts them into the SQLite database as they're collected.
+// All reviews of the product are stored in a single transaction.
+func storeProduct(
+	ctx context.Context,
+	scraper *trustpilot.Scraper,
+	productName string,
+	cfg *config,
+	observe func(review *trustpilot.Review) error,
+) (err error) {
+	db, err := openReviewsDB(ctx, cfg.db)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// the transaction must survive the cancellation of the scraping, so the reviews collected so far are stored
+	tx, err := db.BeginTx(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	stmt, err := tx.Prepare(upsertReview)
+	if err != nil {
+		tx.Rollback()
+
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	count := 0
+	scrapeErr := scraper.ReviewsFunc(ctx, productName, func(review *trustpilot.Review) error {
+		values, err := reviewValues(productName, review)
+		if err != nil {
+			return fmt.Errorf("encode review %s: %w", review.Key(), err)
+		}
+
+		if _, err := stmt.Exec(values...); err != nil {
+			return fmt.Errorf("store review %s: %w", review.Key(), err)
+		}
+
+		count++
+
+		return observe(review)
+	})
+	if scrapeErr != nil && !trustpilot.IsPartial(scrapeErr) {
+		tx.Rollback()
+
+		if errors.Is(scrapeErr, trustpilot.ErrDisallowedByRobots) {
+			return fmt.Errorf("scrape reviews: %w, pass -ignore-robots to scrape anyway", scrapeErr)
+		}
+
+		return fmt.Errorf("scrape reviews: %w", scrapeErr)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit reviews: %w", err)
+	}
+
+	slog.Info("Successfully stored reviews", "product", productName, "reviews", count, "database", cfg.db)
+
+	return scrapeErr
+}
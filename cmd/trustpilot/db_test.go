@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// firstReviewsTable is the reviews table of the first version, before the added columns.
+const firstReviewsTable = `CREATE TABLE reviews (
+	id                     TEXT PRIMARY KEY,
+	product                TEXT NOT NULL,
+	text                   TEXT,
+	date                   TEXT,
+	parsed_date            TIMESTAMP,
+	rating                 TEXT,
+	stars                  INTEGER,
+	title                  TEXT,
+	link                   TEXT,
+	author                 TEXT,
+	country                TEXT,
+	author_review_count    INTEGER,
+	verified               BOOLEAN,
+	reply_text             TEXT,
+	reply_date             TEXT,
+	experience_date        TEXT,
+	parsed_experience_date TIMESTAMP
+)`
+
+func TestOpenReviewsDBMigratesTable(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "reviews.db")
+
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.ExecContext(ctx, firstReviewsTable); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.ExecContext(ctx, `INSERT INTO reviews (id, product, text) VALUES ('1', 'example.com', 'Stored before')`); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	db, err := openReviewsDB(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// the migration runs on every open, so it must skip the columns already added
+	if err := migrateReviewsTable(ctx, db); err != nil {
+		t.Fatalf("migrate the migrated table: %v", err)
+	}
+
+	review := &trustpilot.Review{
+		ID:           "1",
+		Text:         "Updated after the migration",
+		Invited:      true,
+		Useful:       7,
+		Images:       []string{"https://user-images.trustpilot.com/1/a.jpg", "https://user-images.trustpilot.com/1/b.jpg"},
+		Language:     "en",
+		ReplyLatency: 36 * time.Hour,
+	}
+	values, err := reviewValues("example.com", review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, upsertReview, values...); err != nil {
+		t.Fatalf("upsert into the migrated table: %v", err)
+	}
+
+	var (
+		text, images, language string
+		invited                bool
+		useful, latency        int64
+	)
+	err = db.QueryRowContext(ctx, `SELECT text, invited, useful, images, language, reply_latency_seconds FROM reviews WHERE id = '1'`).
+		Scan(&text, &invited, &useful, &images, &language, &latency)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text != review.Text || !invited || useful != 7 || language != "en" || latency != 36*60*60 {
+		t.Errorf("got text %q, invited %t, useful %d, language %q, reply latency %ds", text, invited, useful, language, latency)
+	}
+
+	if want := `["https://user-images.trustpilot.com/1/a.jpg","https://user-images.trustpilot.com/1/b.jpg"]`; images != want {
+		t.Errorf("got images %s, want %s", images, want)
+	}
+}
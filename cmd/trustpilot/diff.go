@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const (
+	diffFormatText = "text"
+	diffFormatJSON = "json"
+)
+
+// reviewsDiff is the difference between the reviews of two runs.
+type reviewsDiff struct {
+	Added    []*trustpilot.Review `json:"added"`
+	Removed  []*trustpilot.Review `json:"removed"`
+	Modified []*reviewChange      `json:"modified"`
+}
+
+// reviewChange is a review present in both runs with different content.
+type reviewChange struct {
+	ID string `json:"id"`
+	// Fields are the names of the changed fields, like "reply"
+	Fields []string           `json:"fields"`
+	Old    *trustpilot.Review `json:"old"`
+	New    *trustpilot.Review `json:"new"`
+}
+
+// runDiff compares two json outputs and writes the difference to w.
+func runDiff(w io.Writer, cfg *config) error {
+	oldReviews, err := loadPreviousReviews(cfg.diffFiles[0])
+	if err != nil {
+		return err
+	}
+
+	newReviews, err := loadPreviousReviews(cfg.diffFiles[1])
+	if err != nil {
+		return err
+	}
+
+	diff := diffReviews(oldReviews.Reviews, newReviews.Reviews)
+	if cfg.diffFormat == diffFormatJSON {
+		return newJSONEncoder(w, cfg).Encode(diff)
+	}
+
+	return writeDiffSummary(w, diff)
+}
+
+// diffReviews matches the reviews by ID and reports the added, removed and modified ones in the order of the runs.
+func diffReviews(oldReviews, newReviews []*trustpilot.Review) *reviewsDiff {
+	diff := &reviewsDiff{
+		Added:    make([]*trustpilot.Review, 0),
+		Removed:  make([]*trustpilot.Review, 0),
+		Modified: make([]*reviewChange, 0),
+	}
+
+	oldByKey := make(map[string]*trustpilot.Review, len(oldReviews))
+	for _, review := range oldReviews {
+		oldByKey[review.Key()] = review
+	}
+
+	newKeys := make(map[string]struct{}, len(newReviews))
+	for _, review := range newReviews {
+		key := review.Key()
+		newKeys[key] = struct{}{}
+
+		old, ok := oldByKey[key]
+		if !ok {
+			diff.Added = append(diff.Added, review)
+
+			continue
+		}
+
+		if fields := changedFields(old, review); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, &reviewChange{ID: key, Fields: fields, Old: old, New: review})
+		}
+	}
+
+	for _, review := range oldReviews {
+		if _, ok := newKeys[review.Key()]; !ok {
+			diff.Removed = append(diff.Removed, review)
+		}
+	}
+
+	return diff
+}
+
+// changedFields returns the names of the fields which differ between the runs. Parsed values are skipped,
+// as they follow the original ones.
+func changedFields(old, updated *trustpilot.Review) []string {
+	fields := make([]string, 0)
+	compare := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+
+	compare("text", old.Text != updated.Text)
+	compare("title", old.Title != updated.Title)
+	compare("date", old.Date != updated.Date)
+	compare("stars", old.Stars != updated.Stars)
+	compare("author", old.Author != updated.Author)
+	compare("country", old.Country != updated.Country)
+	compare("verified", old.Verified != updated.Verified)
+	compare("experience_date", old.ExperienceDate != updated.ExperienceDate)
+	compare("reply", replyOf(old) != replyOf(updated))
+
+	return fields
+}
+
+func replyOf(review *trustpilot.Review) trustpilot.Reply {
+	if review.Reply == nil {
+		return trustpilot.Reply{}
+	}
+
+	return *review.Reply
+}
+
+// writeDiffSummary writes the human-readable difference, one review per line.
+func writeDiffSummary(w io.Writer, diff *reviewsDiff) error {
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d modified reviews\n", len(diff.Added), len(diff.Removed), len(diff.Modified))
+	if err != nil {
+		return err
+	}
+
+	for _, review := range diff.Added {
+		if _, err := fmt.Fprintf(w, "+ %s (%d stars) %q\n", review.Key(), review.Stars, review.Title); err != nil {
+			return err
+		}
+	}
+
+	for _, review := range diff.Removed {
+		if _, err := fmt.Fprintf(w, "- %s (%d stars) %q\n", review.Key(), review.Stars, review.Title); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range diff.Modified {
+		if _, err := fmt.Fprintf(w, "~ %s changed %v\n", change.ID, change.Fields); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
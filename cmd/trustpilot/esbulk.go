@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// esBulkAction is the action line of the Elasticsearch bulk API which precedes every review document.
+type esBulkAction struct {
+	Index esBulkMetadata `json:"index"`
+}
+
+type esBulkMetadata struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+// writeESBulk writes the reviews in the Elasticsearch bulk format: an index action line followed by the review line.
+// The review key is the document ID, so indexing the same reviews again updates them instead of duplicating.
+func writeESBulk(w io.Writer, index string, productReviews *trustpilot.ProductReviews) error {
+	jsonEncoder := newRawJSONEncoder(w)
+	for _, review := range productReviews.Reviews {
+		if err := jsonEncoder.Encode(&esBulkAction{Index: esBulkMetadata{Index: index, ID: review.Key()}}); err != nil {
+			return err
+		}
+
+		if err := jsonEncoder.Encode(review); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// histogram counts the scraped reviews per number of stars. A nil histogram counts nothing.
+type histogram struct {
+	counts [maxStars + 1]int
+}
+
+// histogramBucket is the number and the share of the reviews rated with the stars.
+type histogramBucket struct {
+	Stars   int     `json:"stars"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+func (h *histogram) add(review *trustpilot.Review) {
+	if h == nil || review.Stars < minStars || review.Stars > maxStars {
+		return
+	}
+
+	h.counts[review.Stars]++
+}
+
+// buckets returns the buckets of every number of stars, the percents are of the rated reviews.
+func (h *histogram) buckets() []histogramBucket {
+	rated := 0
+	for stars := minStars; stars <= maxStars; stars++ {
+		rated += h.counts[stars]
+	}
+
+	buckets := make([]histogramBucket, 0, maxStars)
+	for stars := minStars; stars <= maxStars; stars++ {
+		bucket := histogramBucket{Stars: stars, Count: h.counts[stars]}
+		if rated > 0 {
+			bucket.Percent = float64(bucket.Count) / float64(rated) * 100
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
+
+// histogramPath places the histogram next to the output file, in csv for the csv output and json otherwise.
+func histogramPath(cfg *config, productName string) string {
+	dir := "."
+	if cfg.output != "" && cfg.output != stdoutOutput {
+		dir = filepath.Dir(cfg.output)
+	}
+
+	format := formatJSON
+	if cfg.format == formatCSV {
+		format = formatCSV
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("trustpilot_histogram_%s.%s", productName, format))
+}
+
+// write writes the histogram of the product into its file.
+func (h *histogram) write(cfg *config, productName string) (err error) {
+	path := histogramPath(cfg, productName)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("cannot create histogram directory %s: %w", dir, err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot open histogram file %s for writing: %w", path, err)
+	}
+	defer closeOutput(file, &err)
+
+	buckets := h.buckets()
+	if cfg.format != formatCSV {
+		return newJSONEncoder(file, cfg).Encode(buckets)
+	}
+
+	csvWriter := csv.NewWriter(file)
+	if err := csvWriter.Write([]string{"stars", "count", "percent"}); err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		record := []string{
+			strconv.Itoa(bucket.Stars),
+			strconv.Itoa(bucket.Count),
+			strconv.FormatFloat(bucket.Percent, 'f', 2, 64),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
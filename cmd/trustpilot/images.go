@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// unsafeFileNameRe matches the characters which are not safe in a directory name on every platform,
+// like the colon of the hash keys of the reviews without an ID.
+var unsafeFileNameRe = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// downloadImages downloads the images of the review into a directory named after the review in dir.
+// The images downloaded by a previous run are kept, so only the new ones are requested.
+func downloadImages(ctx context.Context, scraper *trustpilot.Scraper, dir string, review *trustpilot.Review) error {
+	if len(review.Images) == 0 {
+		return nil
+	}
+
+	reviewDir := filepath.Join(dir, unsafeFileNameRe.ReplaceAllString(review.Key(), "_"))
+	if err := os.MkdirAll(reviewDir, 0o755); err != nil {
+		return fmt.Errorf("create image directory: %w", err)
+	}
+
+	for i, imageURL := range review.Images {
+		name := strconv.Itoa(i + 1)
+		if parsed, err := url.Parse(imageURL); err == nil {
+			name += path.Ext(parsed.Path)
+		}
+
+		imagePath := filepath.Join(reviewDir, name)
+		if _, err := os.Stat(imagePath); err == nil {
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		if err := downloadFile(ctx, scraper, imageURL, imagePath); err != nil {
+			return fmt.Errorf("download image %s: %w", imageURL, err)
+		}
+
+		slog.Debug("Downloaded review image", "review", review.Key(), "url", imageURL, "path", imagePath)
+	}
+
+	return nil
+}
+
+// downloadFile downloads the file into a temporary file and renames it, so a failed download doesn't leave
+// a truncated image which the next run would take for a downloaded one.
+func downloadFile(ctx context.Context, scraper *trustpilot.Scraper, fileURL, filePath string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := scraper.Download(ctx, fileURL, tmp); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filePath)
+}
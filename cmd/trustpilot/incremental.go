@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// loadPreviousReviews reads the product reviews written by a previous run in json format.
+func loadPreviousReviews(path string) (*trustpilot.ProductReviews, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open previous reviews: %w", err)
+	}
+	defer file.Close()
+
+	previous := &trustpilot.ProductReviews{}
+	if err := json.NewDecoder(file).Decode(previous); err != nil {
+		return nil, fmt.Errorf("decode previous reviews %s: %w", path, err)
+	}
+
+	return previous, nil
+}
+
+// newestReviewDate returns the date of the newest review, or the zero time when there are no dated reviews.
+func newestReviewDate(reviews []*trustpilot.Review) time.Time {
+	newest := time.Time{}
+	for _, review := range reviews {
+		date := review.ParsedDate
+		// files written before the dates were parsed have only the original datetime
+		if date.IsZero() {
+			date, _ = time.Parse(time.RFC3339, review.Date)
+		}
+
+		if date.After(newest) {
+			newest = date
+		}
+	}
+
+	return newest
+}
+
+// mergeReviews combines the newly scraped reviews with the previous ones. New reviews go first,
+// so they win over the previous versions of the same reviews, e.g. with a reply added since.
+func mergeReviews(current, previous *trustpilot.ProductReviews, order trustpilot.SortOrder) *trustpilot.ProductReviews {
+	combined := make([]*trustpilot.Review, 0, len(current.Reviews)+len(previous.Reviews))
+	combined = append(combined, current.Reviews...)
+	combined = append(combined, previous.Reviews...)
+
+	combined, _ = trustpilot.DeduplicateReviews(combined)
+	trustpilot.SortReviews(combined, order)
+
+	return &trustpilot.ProductReviews{
+		ProductName: current.ProductName,
+		Reviews:     combined,
+		Stats:       trustpilot.ComputeStats(combined),
+		Business:    current.Business,
+		FailedPages: current.FailedPages,
+	}
+}
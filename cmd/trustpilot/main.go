@@ -1,238 +1,343 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"net/http"
+	"io"
+	"log/slog"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
-	"sync"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/PuerkitoBio/goquery"
+	"github.com/boodyvo/scraping/pkg/trustpilot"
 )
 
-const (
-	scrapingURL     = "https://www.trustpilot.com/review/%s"
-	scrapingPageURL = "https://www.trustpilot.com/review/%s?page=%d"
-	productName     = "invideo.io"
-)
-
-type Review struct {
-	Text   string `json:"text"`
-	Date   string `json:"date"`
-	Rating string `json:"rating"`
-	Title  string `json:"title"`
-	Link   string `json:"link"`
-}
-
-type ProductReviews struct {
-	ProductName string    `json:"product_name"`
-	Reviews     []*Review `json:"reviews"`
-}
-
 func main() {
-	log.Printf("Start scraping reviews for %s", productName)
-
-	productReviews, err := getProductReviews(productName)
-	if err != nil {
-		log.Fatal(err)
+	if err := run(); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
 	}
+}
 
-	jsonFile, err := os.Create(fmt.Sprintf("trustpilot_reviews_%s.json", productName))
+func run() error {
+	cfg, err := parseConfig()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer jsonFile.Close()
 
-	jsonEncoder := json.NewEncoder(jsonFile)
-	err = jsonEncoder.Encode(productReviews)
-	if err != nil {
-		log.Fatal(err)
+	slog.SetDefault(newLogger(cfg))
+
+	if cfg.diff {
+		return runDiff(os.Stdout, cfg)
 	}
 
-	log.Printf("Successfully scraped %d reviews for %s", len(productReviews.Reviews), productName)
-}
+	if cfg.sinceFile != "" {
+		cfg.previous, err = loadPreviousReviews(cfg.sinceFile)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("Loaded previous reviews, scraping newer reviews only",
+			"reviews", len(cfg.previous.Reviews), "newer_than", newestReviewDate(cfg.previous.Reviews))
+	}
 
-func getProductReviews(name string) (*ProductReviews, error) {
-	log.Printf("Start scraping page 1 for %s", name)
+	// Ctrl-C stops the scraping, but the reviews collected so far are still written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	productURL := fmt.Sprintf(scrapingURL, name)
-	// make a request to the product page
-	res, err := http.Get(productURL)
-	if err != nil {
-		return nil, err
+	// the deadline stops the scraping the same way, so the partial results are written as well
+	if cfg.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.deadline)
+		defer cancel()
 	}
-	defer res.Body.Close()
 
-	// transform the HTML document into a goquery document which will allow us to use a jquery-like syntax
-	doc, err := goquery.NewDocumentFromReader(res.Body)
-	if err != nil {
-		return nil, err
+	if cfg.serve != "" {
+		return serve(ctx, cfg)
 	}
 
-	reviews := make([]*Review, 0)
-	// we synchronize reviews processing with a channel, as we scrape reviews from multiple pages in parallel
-	reviewsChan := make(chan *Review)
-	quitChan := make(chan struct{})
+	scraper := trustpilot.NewScraper(scraperOptions(cfg)...)
 
-	// we append reviews in a separate goroutine from reviewsChan
-	go func() {
-		for review := range reviewsChan {
-			reviews = append(reviews, review)
+	scrape := scrapeProduct
+	if cfg.dryRun {
+		scrape = countProduct
+	}
+
+	// we don't stop on the first failure, so the other products are still scraped. The products share the scraper,
+	// so the parallel ones stay within the same rate limit
+	failed := scraper.EachProduct(ctx, cfg.products, func(ctx context.Context, productName string) error {
+		err := scrape(ctx, scraper, productName, cfg)
+		if err != nil {
+			slog.Error("Cannot scrape reviews", "product", productName, "error", err)
 		}
 
-		close(quitChan)
-	}()
+		return err
+	})
 
-	// to avoid one extra request, we process first page here separately
-	doc.Find("div").Each(extractReviewFunc(reviewsChan, productURL))
+	if cfg.required != nil && cfg.required.dropped.Load() > 0 {
+		slog.Info("Dropped reviews missing required fields", "reviews", cfg.required.dropped.Load(), "fields", cfg.required.fields)
+	}
 
-	// we need to find a link to last page and extract the number of pages for the product
-	doc.Find("a[name='pagination-button-last']").Each(extractReviewsOverPagesFunc(reviewsChan, name))
+	if len(cfg.products) > 1 {
+		slog.Info("Scraped products", "succeeded", len(cfg.products)-len(failed), "total", len(cfg.products))
+		for _, productName := range cfg.products {
+			if err, ok := failed[productName]; ok {
+				slog.Error("Product failed", "product", productName, "error", err)
+			}
+		}
+	}
 
-	close(reviewsChan)
+	if len(failed) > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("deadline of %s reached, partial results: %d of %d products are incomplete or not scraped",
+			cfg.deadline, len(failed), len(cfg.products))
+	}
 
-	// wait until all reviews are appended
-	<-quitChan
+	if len(failed) > 0 {
+		return fmt.Errorf("cannot scrape %d of %d products", len(failed), len(cfg.products))
+	}
 
-	return &ProductReviews{
-		ProductName: name,
-		Reviews:     reviews,
-	}, nil
+	return nil
 }
 
-func extractReviewsOverPagesFunc(reviews chan<- *Review, name string) func(i int, s *goquery.Selection) {
-	return func(i int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if !exists {
-			return
-		}
+func scrapeProduct(ctx context.Context, scraper *trustpilot.Scraper, productName string, cfg *config) error {
+	slog.Info("Start scraping reviews", "product", productName)
 
-		// we need to find a link to pages and extract the number of pages for the product
-		match, err := regexp.MatchString("page=\\d+", href)
-		if err != nil || !match {
-			return
-		}
+	var check *completeness
+	if cfg.validate {
+		check = &completeness{}
+	}
 
-		re := regexp.MustCompile("\\d+")
-		lastPage := re.FindString(href)
-		lastPageInt, err := strconv.Atoi(lastPage)
-		if err != nil {
-			log.Printf("Cannot parse last page %s: %s\n", lastPage, err)
+	var hist *histogram
+	if cfg.histogram {
+		hist = &histogram{}
+	}
 
-			return
+	observe := func(review *trustpilot.Review) error {
+		check.add(review)
+		hist.add(review)
+
+		// a missing image doesn't make the reviews incomplete, so it doesn't fail the product.
+		// After a cancellation the images are skipped, as every download would fail anyway
+		if cfg.imagesDir != "" && ctx.Err() == nil {
+			if err := downloadImages(ctx, scraper, cfg.imagesDir, review); err != nil {
+				slog.Warn("Cannot download review images", "product", productName, "review", review.Key(), "error", err)
+			}
 		}
 
-		// scrape all pages in parallel
-		wg := &sync.WaitGroup{}
-		for i := 2; i <= lastPageInt; i++ {
-			wg.Add(1)
-			go func(pageNumber int) {
-				defer wg.Done()
+		return nil
+	}
+
+	var hook *webhook
+	if cfg.webhook != "" {
+		hook = newWebhook(ctx, cfg, productName)
+	}
 
-				pageReviews, err := getPageProductReviews(name, pageNumber)
-				if err != nil {
-					log.Printf("Cannot get page %d product reviews: %s", pageNumber, err)
+	err := collectProduct(ctx, scraper, productName, cfg, observe, hook)
+	// the webhook failures don't stop the output, so they're reported once the output is written
+	if hook != nil {
+		if hookErr := hook.close(); hookErr != nil && err == nil {
+			err = fmt.Errorf("post reviews: %w", hookErr)
+		}
 
-					return
-				}
+		slog.Info("Posted reviews to the webhook", "product", productName, "reviews", hook.sent)
+	}
 
-				for _, review := range pageReviews {
-					reviews <- review
-				}
-			}(i)
+	if hist != nil && (err == nil || trustpilot.IsPartial(err)) {
+		if histErr := hist.write(cfg, productName); histErr != nil {
+			return fmt.Errorf("write histogram: %w", histErr)
 		}
+	}
 
-		wg.Wait()
+	// the failed pages are recorded in the output, so they fail the product only when completeness is required
+	var pagesErr *trustpilot.PagesError
+	if errors.As(err, &pagesErr) && !cfg.failOnIncomplete {
+		slog.Warn("Reviews are incomplete", "product", productName, "failed_pages", pagesErr.Pages(), "error", err)
+		err = nil
 	}
+
+	if err != nil {
+		return err
+	}
+
+	return check.validate(productName, cfg.validateThreshold, cfg.previous != nil)
 }
 
-func getPageProductReviews(name string, page int) ([]*Review, error) {
-	log.Printf("Start scraping page %d for %s", page, name)
+// collectProduct scrapes the product reviews into the configured output. Every scraped review is passed to observe
+// and posted to the hook unless it's nil. The hook gets the reviews as they arrive, while observe gets them
+// as the output does: once the scraping is done, unless the output is written while scraping anyway.
+func collectProduct(
+	ctx context.Context,
+	scraper *trustpilot.Scraper,
+	productName string,
+	cfg *config,
+	observe func(review *trustpilot.Review) error,
+	hook *webhook,
+) (err error) {
+	streamed := func(review *trustpilot.Review) error {
+		hook.add(review)
+
+		return observe(review)
+	}
 
-	// productURL is used to construct a link to the review. It's pure, without query params
-	productURL := fmt.Sprintf(scrapingURL, name)
-	// actual request URL for scraping a page
-	productRequestURL := fmt.Sprintf(scrapingPageURL, name, page)
-	res, err := http.Get(productRequestURL)
+	// the database backend replaces the file output and stores the reviews as they're collected
+	if cfg.db != "" {
+		return storeProduct(ctx, scraper, productName, cfg, streamed)
+	}
+
+	if cfg.noFile {
+		return observeProduct(ctx, scraper, productName, streamed)
+	}
+
+	// ndjson is written while scraping, so we don't wait for all reviews to be collected
+	if cfg.format == formatNDJSON {
+		return streamProduct(ctx, scraper, productName, cfg, streamed)
+	}
+
+	// on failed pages we still write the reviews of the other pages, but report the product as failed
+	productReviews, err := scraper.CollectReviews(ctx, productName, func(review *trustpilot.Review) error {
+		hook.add(review)
+
+		return nil
+	})
+	if errors.Is(err, trustpilot.ErrDisallowedByRobots) {
+		return fmt.Errorf("scrape reviews: %w, pass -ignore-robots to scrape anyway", err)
+	}
+
+	if err != nil && !trustpilot.IsPartial(err) {
+		return fmt.Errorf("scrape reviews: %w", err)
+	}
+	scrapeErr := err
+	scraped := productReviews.Reviews
+
+	// an incomplete scraping is reported on its own, so only the complete ones are cross-checked
+	var totalErr error
+	if scrapeErr == nil {
+		totalErr = crossCheckTotal(productName, productReviews, cfg)
+	}
+
+	if cfg.previous != nil {
+		slog.Info("Scraped new reviews", "product", productName, "reviews", len(productReviews.Reviews))
+		productReviews = mergeReviews(productReviews, cfg.previous, cfg.sortOrder)
+	}
+
+	output, err := openOutput(cfg, productName)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("open output: %w", err)
 	}
-	defer res.Body.Close()
+	defer closeOutput(output, &err)
 
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	if cfg.statsOnly {
+		err = writeStats(output, cfg, productReviews)
+	} else {
+		err = writeReviews(output, cfg, productReviews)
+	}
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("write reviews: %w", err)
 	}
 
-	reviews := make([]*Review, 0)
-	reviewsChan := make(chan *Review)
-	quitChan := make(chan struct{})
+	slog.Info("Successfully scraped reviews", "product", productName, "reviews", len(productReviews.Reviews))
 
-	go func() {
-		for review := range reviewsChan {
-			reviews = append(reviews, review)
+	// only the newly scraped reviews are observed, without the merged previous ones
+	for _, review := range scraped {
+		if err := observe(review); err != nil {
+			return err
 		}
+	}
+
+	if scrapeErr != nil {
+		return scrapeErr
+	}
+
+	return totalErr
+}
+
+func streamProduct(
+	ctx context.Context,
+	scraper *trustpilot.Scraper,
+	productName string,
+	cfg *config,
+	observe func(review *trustpilot.Review) error,
+) (err error) {
+	output, err := openOutput(cfg, productName)
+	if err != nil {
+		return fmt.Errorf("open output: %w", err)
+	}
+	defer closeOutput(output, &err)
 
-		close(quitChan)
-	}()
+	count, err := streamNDJSON(ctx, scraper, productName, output, observe)
+	if errors.Is(err, trustpilot.ErrDisallowedByRobots) {
+		return fmt.Errorf("scrape reviews: %w, pass -ignore-robots to scrape anyway", err)
+	}
 
-	// extract reviews from the page
-	doc.Find("div").Each(extractReviewFunc(reviewsChan, productURL))
+	if err != nil && !trustpilot.IsPartial(err) {
+		return fmt.Errorf("scrape reviews: %w", err)
+	}
 
-	close(reviewsChan)
-	<-quitChan
+	slog.Info("Successfully scraped reviews", "product", productName, "reviews", count)
 
-	return reviews, nil
+	return err
 }
 
-func extractReviewFunc(reviews chan<- *Review, productURL string) func(i int, s *goquery.Selection) {
-	return func(i int, s *goquery.Selection) {
-		classes, exists := s.Attr("class")
-		if !exists {
-			return
-		}
+// observeProduct passes the product reviews to observe as they're collected, without writing the output file.
+func observeProduct(
+	ctx context.Context,
+	scraper *trustpilot.Scraper,
+	productName string,
+	observe func(review *trustpilot.Review) error,
+) error {
+	count := 0
+	err := scraper.ReviewsFunc(ctx, productName, func(review *trustpilot.Review) error {
+		count++
+
+		return observe(review)
+	})
+	if errors.Is(err, trustpilot.ErrDisallowedByRobots) {
+		return fmt.Errorf("scrape reviews: %w, pass -ignore-robots to scrape anyway", err)
+	}
 
-		// validate if the div is a review card and a card wrapper (to avoid processing other divs, like advertisement)
-		isReviewCard := false
-		isCardWrapper := false
+	if err != nil && !trustpilot.IsPartial(err) {
+		return fmt.Errorf("scrape reviews: %w", err)
+	}
 
-		for _, class := range strings.Split(classes, " ") {
-			if strings.HasPrefix(class, "styles_reviewCard__") {
-				isReviewCard = true
-			}
+	slog.Info("Successfully scraped reviews", "product", productName, "reviews", count)
 
-			if strings.HasPrefix(class, "styles_cardWrapper__") {
-				isCardWrapper = true
-			}
-		}
+	return err
+}
 
-		if !isReviewCard || !isCardWrapper {
-			return
-		}
+// countProduct prints the number of review pages of the product, which helps to plan the rate limits
+// of the actual scraping.
+func countProduct(ctx context.Context, scraper *trustpilot.Scraper, productName string, cfg *config) error {
+	count, err := scraper.CountPages(ctx, productName)
+	if errors.Is(err, trustpilot.ErrDisallowedByRobots) {
+		return fmt.Errorf("count pages: %w, pass -ignore-robots to scrape anyway", err)
+	}
 
-		// extract review data
-		dateOfPost := s.Find("time").AttrOr("datetime", "")
-		textOfReview := s.Find("p[data-service-review-text-typography]").Text()
+	if err != nil {
+		return fmt.Errorf("count pages: %w", err)
+	}
 
-		title := s.Find("h2").Text()
-		link, _ := s.Find("a[data-review-title-typography]").Attr("href")
-		if link != "" {
-			link = productURL + link
-		}
+	if count.Pages == 0 {
+		fmt.Printf("%s: unknown number of pages, %d reviews per page\n", productName, count.ReviewsPerPage)
 
-		// we don't transform the data in place, as we want to keep the original data for future analysis
-		rating := s.Find("img").AttrOr("alt", "")
+		return nil
+	}
 
-		reviews <- &Review{
-			Text:   textOfReview,
-			Date:   dateOfPost,
-			Rating: rating,
-			Title:  title,
-			Link:   link,
-		}
+	line := fmt.Sprintf("%s: %d pages, about %d reviews", productName, count.Pages, count.EstimatedReviews)
+	if cfg.rps > 0 {
+		line += fmt.Sprintf(", at least %s at %g requests per second",
+			time.Duration(float64(count.Pages)/cfg.rps*float64(time.Second)).Round(time.Second), cfg.rps)
+	}
+	fmt.Println(line)
+
+	return nil
+}
+
+// closeOutput closes the output and reports the close error unless another error already happened,
+// as a failed close may mean the written data was lost.
+func closeOutput(output io.Closer, err *error) {
+	if closeErr := output.Close(); closeErr != nil && *err == nil {
+		*err = fmt.Errorf("close output: %w", closeErr)
 	}
 }
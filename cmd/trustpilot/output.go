@@ -0,0 +1,243 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+	formatXLSX   = "xlsx"
+	formatESBulk = "es-bulk"
+)
+
+const gzipExtension = ".gz"
+
+// utf8BOM is the byte order mark, which Excel needs to read the csv output as UTF-8 rather than the locale encoding.
+const utf8BOM = "\ufeff"
+
+// stdoutOutput is the output path which makes the reviews written to stdout instead of a file.
+const stdoutOutput = "-"
+
+// csvHeader are the columns of the csv and xlsx outputs. The first ones are the original columns, which the existing
+// spreadsheets rely on, so the columns added later go after them.
+var csvHeader = []string{"text", "date", "rating", "title", "link", "id", "stars", "author", "country"}
+
+// validateFormat checks that the output format is supported.
+func validateFormat(format string) error {
+	switch format {
+	case formatJSON, formatCSV, formatNDJSON, formatXLSX, formatESBulk:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q, expected one of: %s, %s, %s, %s, %s",
+			format, formatJSON, formatCSV, formatNDJSON, formatXLSX, formatESBulk)
+	}
+}
+
+// outputFileName builds the default output file name for the product, with the extension of the format.
+func outputFileName(productName, format string) string {
+	// the bulk output is JSON Lines, so it gets their extension
+	if format == formatESBulk {
+		format = formatNDJSON
+	}
+
+	return fmt.Sprintf("trustpilot_reviews_%s.%s", productName, format)
+}
+
+// openOutput opens the destination for the product reviews. An empty path falls back to the default file name,
+// and stdoutOutput selects stdout, which is left open on Close. With gzip enabled, the output is compressed
+// and the file name gets the .gz extension.
+func openOutput(cfg *config, productName string) (io.WriteCloser, error) {
+	output, err := openOutputFile(cfg, productName)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.gzip {
+		output = &gzipWriteCloser{Writer: gzip.NewWriter(output), output: output}
+	}
+
+	if cfg.utf8BOM {
+		if _, err := io.WriteString(output, utf8BOM); err != nil {
+			output.Close()
+
+			return nil, fmt.Errorf("write byte order mark: %w", err)
+		}
+	}
+
+	return output, nil
+}
+
+func openOutputFile(cfg *config, productName string) (io.WriteCloser, error) {
+	path := cfg.output
+	if path == stdoutOutput {
+		return nopWriteCloser{Writer: os.Stdout}, nil
+	}
+
+	if path == "" {
+		path = outputFileName(productName, cfg.format)
+	}
+
+	if cfg.gzip && !strings.HasSuffix(path, gzipExtension) {
+		path += gzipExtension
+	}
+
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		return nil, fmt.Errorf("output path %s is a directory", path)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("cannot create output directory %s: %w", dir, err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open output file %s for writing: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// gzipWriteCloser compresses the output. Close flushes the compressed data before closing the output,
+// otherwise the archive would be truncated.
+type gzipWriteCloser struct {
+	*gzip.Writer
+	output io.Closer
+}
+
+func (g *gzipWriteCloser) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		g.output.Close()
+
+		return err
+	}
+
+	return g.output.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// productStats is the output of the stats-only mode, which omits the reviews.
+type productStats struct {
+	ProductName string              `json:"product_name"`
+	Stats       *trustpilot.Stats   `json:"stats"`
+	Business    trustpilot.Business `json:"business"`
+	FailedPages []int               `json:"failed_pages,omitempty"`
+}
+
+// newJSONEncoder creates the encoder of the json output, indented when the pretty output is requested.
+func newJSONEncoder(w io.Writer, cfg *config) *json.Encoder {
+	jsonEncoder := newRawJSONEncoder(w)
+	if cfg.pretty {
+		jsonEncoder.SetIndent("", "  ")
+	}
+
+	return jsonEncoder
+}
+
+// newRawJSONEncoder creates the encoder which keeps <, > and & of the review text as they are. The output isn't
+// embedded into HTML, so the escaping only makes the text harder to read for the downstream tools.
+func newRawJSONEncoder(w io.Writer) *json.Encoder {
+	jsonEncoder := json.NewEncoder(w)
+	jsonEncoder.SetEscapeHTML(false)
+
+	return jsonEncoder
+}
+
+// writeStats encodes only the statistics of the product reviews into w.
+func writeStats(w io.Writer, cfg *config, productReviews *trustpilot.ProductReviews) error {
+	return newJSONEncoder(w, cfg).Encode(&productStats{
+		ProductName: productReviews.ProductName,
+		Stats:       productReviews.Stats,
+		Business:    productReviews.Business,
+		FailedPages: productReviews.FailedPages,
+	})
+}
+
+// writeReviews encodes the product reviews into w using the configured format.
+func writeReviews(w io.Writer, cfg *config, productReviews *trustpilot.ProductReviews) error {
+	switch cfg.format {
+	case formatCSV:
+		return writeCSV(w, productReviews)
+	case formatXLSX:
+		return writeXLSX(w, productReviews)
+	case formatESBulk:
+		return writeESBulk(w, cfg.esIndex, productReviews)
+	default:
+		return newJSONEncoder(w, cfg).Encode(productReviews)
+	}
+}
+
+// streamNDJSON scrapes the product reviews and writes them into w one JSON object per line as they arrive,
+// so memory stays flat regardless of the number of reviews.
+func streamNDJSON(
+	ctx context.Context,
+	scraper *trustpilot.Scraper,
+	productName string,
+	w io.Writer,
+	observe func(review *trustpilot.Review) error,
+) (int, error) {
+	count := 0
+	// json.Encoder writes every encoded value straight to w, so each review is flushed as soon as it's encoded
+	jsonEncoder := newRawJSONEncoder(w)
+	err := scraper.ReviewsFunc(ctx, productName, func(review *trustpilot.Review) error {
+		count++
+		if err := jsonEncoder.Encode(review); err != nil {
+			return err
+		}
+
+		return observe(review)
+	})
+
+	return count, err
+}
+
+func writeCSV(w io.Writer, productReviews *trustpilot.ProductReviews) error {
+	// csv.Writer quotes fields with newlines, commas and quotes, so multi-line review text stays valid
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, review := range productReviews.Reviews {
+		record := []string{
+			review.Text,
+			review.Date,
+			review.RatingText,
+			review.Title,
+			review.Link,
+			review.ID,
+			strconv.Itoa(review.Stars),
+			review.Author,
+			review.Country,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
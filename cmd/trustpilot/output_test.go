@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// nonASCIIReviews have emoji, accents and HTML special characters, which must reach the output unchanged.
+var nonASCIIReviews = &trustpilot.ProductReviews{
+	ProductName: "example.com",
+	Reviews: []*trustpilot.Review{
+		{
+			ID:         "1",
+			Text:       "Très bien, livraison rapide 🚚👍 <b>&</b> merci!",
+			Date:       "2024-03-01T10:00:00.000Z",
+			RatingText: "Noté 5 sur 5 étoiles",
+			Stars:      5,
+			Title:      "Überraschend gut 😀",
+			Link:       "https://www.trustpilot.com/reviews/1",
+			Author:     "José Núñez",
+			Country:    "ES",
+		},
+		{
+			ID:         "2",
+			Text:       "Zażółć gęślą jaźń\nсъешь же ещё 🍰",
+			Date:       "2024-03-02T10:00:00.000Z",
+			RatingText: "Rated 2 out of 5 stars",
+			Stars:      2,
+			Title:      "Ça dépend",
+			Link:       "https://www.trustpilot.com/reviews/2",
+			Author:     "Zoë Ångström",
+			Country:    "SE",
+		},
+	},
+}
+
+func TestWriteReviewsRoundTrip(t *testing.T) {
+	tests := []struct {
+		format string
+		// read decodes the output into the text, title and author of every review
+		read func(t *testing.T, data []byte) [][]string
+	}{
+		{format: formatJSON, read: readJSONReviews},
+		{format: formatCSV, read: readCSVReviews},
+		{format: formatXLSX, read: readXLSXReviews},
+	}
+
+	var want [][]string
+	for _, review := range nonASCIIReviews.Reviews {
+		want = append(want, []string{review.Text, review.Title, review.Author})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var output bytes.Buffer
+			if err := writeReviews(&output, &config{format: tt.format}, nonASCIIReviews); err != nil {
+				t.Fatal(err)
+			}
+
+			got := tt.read(t, output.Bytes())
+			if !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+				t.Errorf("got reviews %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteCSVColumns(t *testing.T) {
+	var output bytes.Buffer
+	if err := writeCSV(&output, nonASCIIReviews); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&output).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != len(nonASCIIReviews.Reviews)+1 {
+		t.Fatalf("got %d records, want the header and %d reviews", len(records), len(nonASCIIReviews.Reviews))
+	}
+
+	// the original columns come first, so the existing spreadsheets keep working
+	want := []string{"text", "date", "rating", "title", "link"}
+	if !slices.Equal(records[0][:len(want)], want) {
+		t.Fatalf("got header %q, want it to start with %q", records[0], want)
+	}
+
+	review := nonASCIIReviews.Reviews[0]
+	if wantRecord := []string{review.Text, review.Date, review.RatingText, review.Title, review.Link}; !slices.Equal(records[1][:len(want)], wantRecord) {
+		t.Errorf("got record %q, want it to start with %q", records[1], wantRecord)
+	}
+}
+
+func TestWriteReviewsKeepsHTMLCharacters(t *testing.T) {
+	var output bytes.Buffer
+	if err := writeReviews(&output, &config{format: formatJSON}, nonASCIIReviews); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(output.Bytes(), []byte("<b>&</b>")) || !bytes.Contains(output.Bytes(), []byte("🚚👍")) {
+		t.Errorf("json output escapes the review text: %s", output.Bytes())
+	}
+}
+
+func TestOpenOutputWritesByteOrderMark(t *testing.T) {
+	cfg := &config{
+		format:  formatCSV,
+		output:  filepath.Join(t.TempDir(), "reviews.csv"),
+		utf8BOM: true,
+	}
+
+	output, err := openOutput(cfg, nonASCIIReviews.ProductName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeReviews(output, cfg, nonASCIIReviews); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(cfg.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, found := bytes.CutPrefix(data, []byte(utf8BOM))
+	if !found {
+		t.Fatalf("csv output doesn't start with the byte order mark: %q", data[:min(len(data), 16)])
+	}
+
+	if got := readCSVReviews(t, data); len(got) != len(nonASCIIReviews.Reviews) || got[0][0] != nonASCIIReviews.Reviews[0].Text {
+		t.Errorf("got reviews %q after the byte order mark", got)
+	}
+}
+
+func readJSONReviews(t *testing.T, data []byte) [][]string {
+	t.Helper()
+
+	var productReviews trustpilot.ProductReviews
+	if err := json.Unmarshal(data, &productReviews); err != nil {
+		t.Fatal(err)
+	}
+
+	var reviews [][]string
+	for _, review := range productReviews.Reviews {
+		reviews = append(reviews, []string{review.Text, review.Title, review.Author})
+	}
+
+	return reviews
+}
+
+func readCSVReviews(t *testing.T, data []byte) [][]string {
+	t.Helper()
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return reviewColumns(t, records)
+}
+
+func readXLSXReviews(t *testing.T, data []byte) [][]string {
+	t.Helper()
+
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(xlsxSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return reviewColumns(t, rows)
+}
+
+// reviewColumns picks the text, title and author columns of the rows by the header row.
+func reviewColumns(t *testing.T, rows [][]string) [][]string {
+	t.Helper()
+
+	if len(rows) == 0 || !slices.Equal(rows[0], csvHeader) {
+		t.Fatalf("got rows %q, want the %q header first", rows, csvHeader)
+	}
+
+	text, title, author := slices.Index(csvHeader, "text"), slices.Index(csvHeader, "title"), slices.Index(csvHeader, "author")
+
+	var reviews [][]string
+	for _, row := range rows[1:] {
+		reviews = append(reviews, []string{row[text], row[title], row[author]})
+	}
+
+	return reviews
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// reviewFields are the fields -require can check, each reporting whether the review has the field.
+var reviewFields = map[string]func(review *trustpilot.Review) bool{
+	"id":      func(review *trustpilot.Review) bool { return review.ID != "" },
+	"text":    func(review *trustpilot.Review) bool { return strings.TrimSpace(review.Text) != "" },
+	"title":   func(review *trustpilot.Review) bool { return strings.TrimSpace(review.Title) != "" },
+	"date":    func(review *trustpilot.Review) bool { return review.Date != "" },
+	"rating":  func(review *trustpilot.Review) bool { return review.Stars > 0 },
+	"author":  func(review *trustpilot.Review) bool { return strings.TrimSpace(review.Author) != "" },
+	"country": func(review *trustpilot.Review) bool { return review.Country != "" },
+	"link":    func(review *trustpilot.Review) bool { return review.Link != "" },
+}
+
+// requirement drops the reviews missing any of the required fields and counts them. The filter is called
+// from the scraping of every product, which may run in parallel, so the count is atomic.
+type requirement struct {
+	fields  []string
+	dropped atomic.Int64
+}
+
+// parseRequirement parses the comma-separated field names of -require.
+func parseRequirement(value string) (*requirement, error) {
+	req := &requirement{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+
+		if _, ok := reviewFields[field]; !ok {
+			return nil, fmt.Errorf("unsupported -require field %q, expected some of: %s", field, strings.Join(fieldNames(), ", "))
+		}
+
+		req.fields = append(req.fields, field)
+	}
+
+	if len(req.fields) == 0 {
+		return nil, fmt.Errorf("invalid -require %q, expected comma-separated field names", value)
+	}
+
+	return req, nil
+}
+
+// keep reports whether the review has all required fields.
+func (r *requirement) keep(review *trustpilot.Review) bool {
+	for _, field := range r.fields {
+		if !reviewFields[field](review) {
+			r.dropped.Add(1)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(reviewFields))
+	for name := range reviewFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// loadSelectors reads the selectors overrides from a json or yaml file, chosen by the file extension.
+// The selectors missing in the file keep their default values.
+func loadSelectors(path string) (trustpilot.Selectors, error) {
+	selectors := trustpilot.Selectors{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return selectors, fmt.Errorf("read selectors: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &selectors)
+	default:
+		err = json.Unmarshal(data, &selectors)
+	}
+	if err != nil {
+		return selectors, fmt.Errorf("decode selectors %s: %w", path, err)
+	}
+
+	return selectors, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const serveShutdownTimeout = 10 * time.Second
+
+// serve runs an HTTP server scraping the reviews on demand until ctx is done. The scraping metrics
+// are exposed on /metrics.
+func serve(ctx context.Context, cfg *config) error {
+	registry := prometheus.NewRegistry()
+	metrics, err := trustpilot.NewMetrics(registry)
+	if err != nil {
+		return fmt.Errorf("register metrics: %w", err)
+	}
+
+	scraper := trustpilot.NewScraper(append(scraperOptions(cfg), trustpilot.WithMetrics(metrics))...)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/reviews", reviewsHandler(scraper, cfg.serveTimeout))
+
+	server := &http.Server{
+		Addr:              cfg.serve,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		slog.Info("Serving reviews", "address", cfg.serve)
+		errChan <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down the server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), serveShutdownTimeout)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
+}
+
+// reviewsHandler scrapes the reviews of the product from the "product" query parameter. The scraping stops
+// when the client goes away or the timeout passes. Partial results are returned with the X-Incomplete header.
+func reviewsHandler(scraper *trustpilot.Scraper, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+
+			return
+		}
+
+		productName := strings.TrimSpace(r.URL.Query().Get("product"))
+		if productName == "" {
+			writeJSONError(w, http.StatusBadRequest, errors.New("product query parameter is required"))
+
+			return
+		}
+
+		ctx := r.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		productReviews, err := scraper.Reviews(ctx, productName)
+		if err != nil && !trustpilot.IsPartial(err) {
+			slog.Error("Cannot scrape reviews", "product", productName, "error", err)
+			writeJSONError(w, scrapeErrorStatus(err), err)
+
+			return
+		}
+
+		if err != nil {
+			slog.Warn("Returning incomplete reviews", "product", productName, "error", err)
+			w.Header().Set("X-Incomplete", "true")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(productReviews); err != nil {
+			slog.Error("Cannot write reviews response", "product", productName, "error", err)
+		}
+	}
+}
+
+// scrapeErrorStatus maps the scraping error to the status of the response.
+func scrapeErrorStatus(err error) int {
+	var statusErr *trustpilot.StatusError
+	switch {
+	case errors.Is(err, trustpilot.ErrInvalidProduct):
+		return http.StatusBadRequest
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound:
+		return http.StatusNotFound
+	case errors.Is(err, trustpilot.ErrDisallowedByRobots):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
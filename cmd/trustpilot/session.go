@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// netscapeHTTPOnlyPrefix marks the HttpOnly cookies in the Netscape cookie files, which are not comments.
+const netscapeHTTPOnlyPrefix = "#HttpOnly_"
+
+// headersFlag collects the "key=value" headers of the repeated flag.
+type headersFlag http.Header
+
+func (h headersFlag) String() string {
+	pairs := make([]string, 0, len(h))
+	for key, values := range h {
+		for _, value := range values {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (h headersFlag) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if key = strings.TrimSpace(key); !found || key == "" {
+		return fmt.Errorf("invalid header %q, expected key=value", value)
+	}
+
+	http.Header(h).Add(key, strings.TrimSpace(val))
+
+	return nil
+}
+
+// parseCookies reads the cookies from a Netscape cookie file when the value is a path to an existing file,
+// or parses the value as a Cookie header, like "name=value; other=value".
+func parseCookies(value string) ([]*http.Cookie, error) {
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		return loadCookieFile(value)
+	}
+
+	cookies := (&http.Request{Header: http.Header{"Cookie": {value}}}).Cookies()
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("invalid cookies %q, expected name=value pairs or a path to a cookie file", value)
+	}
+
+	return cookies, nil
+}
+
+// loadCookieFile reads the cookies exported by browsers and curl in the Netscape format: tab-separated domain,
+// subdomains flag, path, secure flag, expiration, name and value.
+func loadCookieFile(path string) ([]*http.Cookie, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open cookie file: %w", err)
+	}
+	defer file.Close()
+
+	cookies := make([]*http.Cookie, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		httpOnly := strings.HasPrefix(text, netscapeHTTPOnlyPrefix)
+		text = strings.TrimPrefix(text, netscapeHTTPOnlyPrefix)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookie file %s: line %d has %d fields, expected 7", path, line, len(fields))
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+
+		// zero expiration is a session cookie
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		cookies = append(cookies, cookie)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read cookie file %s: %w", path, err)
+	}
+
+	return cookies, nil
+}
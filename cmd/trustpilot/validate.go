@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+// completeness counts the scraped reviews having the fields every review is expected to have,
+// so a degraded parser is caught even when the scraping itself succeeds. A nil completeness counts nothing.
+type completeness struct {
+	total      int
+	withText   int
+	withDate   int
+	withRating int
+}
+
+func (c *completeness) add(review *trustpilot.Review) {
+	if c == nil {
+		return
+	}
+
+	c.total++
+	if review.Text != "" {
+		c.withText++
+	}
+
+	if review.Date != "" {
+		c.withDate++
+	}
+
+	if review.RatingText != "" || review.Stars > 0 {
+		c.withRating++
+	}
+}
+
+// validate returns an error when the share of the reviews with any of the fields is below the threshold percent,
+// or when no reviews were scraped, unless allowEmpty is set, as for incremental runs without new reviews.
+func (c *completeness) validate(productName string, threshold float64, allowEmpty bool) error {
+	if c == nil || (c.total == 0 && allowEmpty) {
+		return nil
+	}
+
+	if c.total == 0 {
+		return fmt.Errorf("validation failed: no reviews scraped for %s", productName)
+	}
+
+	fields := []struct {
+		name  string
+		count int
+	}{
+		{"text", c.withText},
+		{"date", c.withDate},
+		{"rating", c.withRating},
+	}
+	for _, field := range fields {
+		percent := float64(field.count) / float64(c.total) * 100
+		if percent < threshold {
+			return fmt.Errorf("validation failed: only %.1f%% of %d reviews of %s have %s, expected at least %g%%",
+				percent, c.total, productName, field.name, threshold)
+		}
+	}
+
+	slog.Info("Reviews passed validation", "product", productName, "reviews", c.total)
+
+	return nil
+}
+
+// crossCheckTotal compares the number of the scraped reviews, including the filtered ones, with the total advertised
+// by the business header, which reveals the pages or reviews silently missed by the pagination or the parser.
+// A difference above the tolerance percent is logged, or returned as an error when strict.
+// Nothing is checked when only a part of the reviews is scraped on purpose or the total is unknown.
+func crossCheckTotal(productName string, productReviews *trustpilot.ProductReviews, cfg *config) error {
+	expected := productReviews.Business.TotalReviews
+	if expected == 0 || cfg.startPage > 1 || cfg.endPage > 0 || cfg.maxReviews > 0 || cfg.previous != nil {
+		return nil
+	}
+
+	scraped := len(productReviews.Reviews) + productReviews.Filtered
+	percent := math.Abs(float64(expected-scraped)) / float64(expected) * 100
+	if percent <= cfg.totalTolerance {
+		return nil
+	}
+
+	if !cfg.strict {
+		slog.Warn("Scraped reviews don't match the advertised total", "product", productName,
+			"scraped", scraped, "filtered", productReviews.Filtered, "advertised", expected, "difference_percent", percent)
+
+		return nil
+	}
+
+	return fmt.Errorf("scraped %d reviews of %s, including %d filtered, but %d are advertised: %.1f%% difference, expected at most %g%%",
+		scraped, productName, productReviews.Filtered, expected, percent, cfg.totalTolerance)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const (
+	webhookTimeout    = 30 * time.Second
+	webhookMaxRetries = 3
+	webhookRetryDelay = time.Second
+	// webhookQueue is how many full batches wait for the sender, so a slow endpoint doesn't stall the scraping
+	webhookQueue = 8
+)
+
+// webhookPayload is the body of every webhook request.
+type webhookPayload struct {
+	ProductName string               `json:"product_name"`
+	Reviews     []*trustpilot.Review `json:"reviews"`
+}
+
+// webhook posts the reviews of the product to the URL in batches as they're collected. The batches are posted
+// by a separate goroutine, so neither the scraping waits for the endpoint nor its failures stop the scraping.
+type webhook struct {
+	url         string
+	batchSize   int
+	client      *http.Client
+	productName string
+	batch       []*trustpilot.Review
+	batches     chan []*trustpilot.Review
+	done        chan struct{}
+	// sent and err belong to the sender goroutine until close returns
+	sent int
+	err  error
+}
+
+// newWebhook starts the sender of the product batches. The batches are posted even after ctx is cancelled,
+// as the file output keeps the reviews collected so far too. close must be called to stop the sender.
+func newWebhook(ctx context.Context, cfg *config, productName string) *webhook {
+	w := &webhook{
+		url:         cfg.webhook,
+		batchSize:   cfg.webhookBatch,
+		client:      &http.Client{Timeout: webhookTimeout},
+		productName: productName,
+		batches:     make(chan []*trustpilot.Review, webhookQueue),
+		done:        make(chan struct{}),
+	}
+
+	go w.send(context.WithoutCancel(ctx))
+
+	return w
+}
+
+// add queues the review and hands the batch over to the sender once it's full. It does nothing on a nil webhook.
+func (w *webhook) add(review *trustpilot.Review) {
+	if w == nil {
+		return
+	}
+
+	w.batch = append(w.batch, review)
+	if len(w.batch) < w.batchSize {
+		return
+	}
+
+	w.batches <- w.batch
+	w.batch = nil
+}
+
+// close posts the rest of the reviews, waits until all batches are posted and returns the first failure.
+func (w *webhook) close() error {
+	if len(w.batch) > 0 {
+		w.batches <- w.batch
+		w.batch = nil
+	}
+
+	close(w.batches)
+	<-w.done
+
+	return w.err
+}
+
+// send posts the queued batches. A failed batch doesn't stop the next ones, as they may still get through.
+func (w *webhook) send(ctx context.Context) {
+	defer close(w.done)
+
+	for batch := range w.batches {
+		if err := w.postBatch(ctx, batch); err != nil {
+			slog.Error("Cannot post reviews to the webhook", "product", w.productName, "reviews", len(batch), "error", err)
+			if w.err == nil {
+				w.err = err
+			}
+
+			continue
+		}
+
+		w.sent += len(batch)
+	}
+}
+
+// postBatch encodes and posts the batch.
+func (w *webhook) postBatch(ctx context.Context, batch []*trustpilot.Review) error {
+	body, err := json.Marshal(&webhookPayload{ProductName: w.productName, Reviews: batch})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	return w.post(ctx, body)
+}
+
+// post sends the body, retrying network errors and 5xx or 429 responses with a growing delay.
+func (w *webhook) post(ctx context.Context, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= webhookMaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := webhookRetryDelay << (attempt - 1)
+			slog.Warn("Retrying webhook request", "url", w.url, "delay", delay, "attempt", attempt+1, "error", lastErr)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("create webhook request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := w.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			lastErr = err
+
+			continue
+		}
+
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		switch {
+		case res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices:
+			return nil
+		case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+			lastErr = fmt.Errorf("webhook responded with status %s", res.Status)
+		default:
+			return fmt.Errorf("webhook %s responded with status %s", w.url, res.Status)
+		}
+	}
+
+	return fmt.Errorf("webhook %s failed after %d attempts: %w", w.url, webhookMaxRetries+1, lastErr)
+}
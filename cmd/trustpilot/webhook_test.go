@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+func TestWebhookKeepsPostingAfterFailedBatch(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		received []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		// 400 isn't retried, so only the first batch is lost
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		for _, review := range payload.Reviews {
+			received = append(received, review.ID)
+		}
+	}))
+	defer server.Close()
+
+	hook := newWebhook(context.Background(), &config{webhook: server.URL, webhookBatch: 2}, "example.com")
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		hook.add(&trustpilot.Review{ID: id})
+	}
+
+	if err := hook.close(); err == nil {
+		t.Error("expected the failed batch to be reported")
+	}
+
+	if hook.sent != 3 || len(received) != 3 {
+		t.Errorf("sent %d reviews, the endpoint received %v, want 3, 4 and 5", hook.sent, received)
+	}
+}
+
+func TestWebhookPostsAfterCancellation(t *testing.T) {
+	var posted int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		posted += len(payload.Reviews)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hook := newWebhook(ctx, &config{webhook: server.URL, webhookBatch: 2}, "example.com")
+	hook.add(&trustpilot.Review{ID: "1"})
+	cancel()
+	hook.add(&trustpilot.Review{ID: "2"})
+	hook.add(&trustpilot.Review{ID: "3"})
+
+	if err := hook.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if posted != 3 {
+		t.Errorf("posted %d reviews after the cancellation, want 3", posted)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/boodyvo/scraping/pkg/trustpilot"
+)
+
+const (
+	xlsxSheet = "Reviews"
+	// xlsxMaxColumnWidth keeps the columns of long texts readable, the cells wrap the rest
+	xlsxMaxColumnWidth = 80
+	xlsxMinColumnWidth = 8
+)
+
+// writeXLSX writes the reviews into an Excel sheet with a frozen header row and one review per row.
+func writeXLSX(w io.Writer, productReviews *trustpilot.ProductReviews) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName(file.GetSheetName(0), xlsxSheet); err != nil {
+		return err
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F7A4D"}},
+		Alignment: &excelize.Alignment{Vertical: "center"},
+	})
+	if err != nil {
+		return err
+	}
+
+	widths := make([]int, len(csvHeader))
+	setRow := func(row int, values []interface{}) error {
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+
+		for i, value := range values {
+			if text, ok := value.(string); ok {
+				widths[i] = max(widths[i], utf8.RuneCountInString(text))
+			}
+		}
+
+		return file.SetSheetRow(xlsxSheet, cell, &values)
+	}
+
+	header := make([]interface{}, 0, len(csvHeader))
+	for _, name := range csvHeader {
+		header = append(header, name)
+	}
+	if err := setRow(1, header); err != nil {
+		return err
+	}
+
+	for i, review := range productReviews.Reviews {
+		// stars stay a number, so the sheet can be sorted and averaged by them
+		err := setRow(i+2, []interface{}{
+			review.Text,
+			review.Date,
+			review.RatingText,
+			review.Title,
+			review.Link,
+			review.ID,
+			review.Stars,
+			review.Author,
+			review.Country,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	lastHeaderCell, err := excelize.CoordinatesToCellName(len(csvHeader), 1)
+	if err != nil {
+		return err
+	}
+
+	if err := file.SetCellStyle(xlsxSheet, "A1", lastHeaderCell, headerStyle); err != nil {
+		return err
+	}
+
+	for i, width := range widths {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+
+		width = min(max(width+2, xlsxMinColumnWidth), xlsxMaxColumnWidth)
+		if err := file.SetColWidth(xlsxSheet, column, column, float64(width)); err != nil {
+			return err
+		}
+	}
+
+	err = file.SetPanes(xlsxSheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+	if err != nil {
+		return err
+	}
+
+	return file.Write(w)
+}
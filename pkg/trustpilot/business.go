@@ -0,0 +1,59 @@
+package trustpilot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var decimalRe = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// Business is the aggregate information about the business shown in the header of its review page.
+type Business struct {
+	// TrustScore is the Trustpilot score of the business from 1 to 5, zero when it's absent.
+	TrustScore float64 `json:"trust_score"`
+	// TotalReviews is the number of all reviews of the business, not only the scraped ones.
+	TotalReviews int `json:"total_reviews"`
+	// StarRating is the rating shown by the stars of the header, rounded to the half of a star.
+	StarRating float64 `json:"star_rating"`
+	// CategoryNames are the Trustpilot categories the business is listed in.
+	CategoryNames []string `json:"category_names"`
+}
+
+// parseBusiness extracts the business information from the header of the review page.
+// The fields which cannot be found are left zeroed.
+func parseBusiness(doc *goquery.Document) Business {
+	business := Business{
+		TrustScore: parseDecimal(doc.Find("[data-rating-typography]").First().Text()),
+		// the count is formatted with thousands separators, like "Reviews 12,345"
+		TotalReviews: parseFirstNumber(strings.NewReplacer(",", "", ".", "", " ", "").
+			Replace(doc.Find("[data-reviews-count-typography]").First().Text())),
+		StarRating: parseDecimal(doc.Find("img[alt^='TrustScore']").First().AttrOr("alt", "")),
+	}
+
+	seen := make(map[string]bool)
+	doc.Find("a[href*='/categories/']").Each(func(i int, link *goquery.Selection) {
+		name := strings.TrimSpace(link.Text())
+		if name == "" || seen[name] {
+			return
+		}
+
+		seen[name] = true
+		business.CategoryNames = append(business.CategoryNames, name)
+	})
+
+	return business
+}
+
+// parseDecimal parses the first decimal number of the text, like 4.5 in "TrustScore 4.5 out of 5".
+// It returns 0 when there is no number in the text.
+func parseDecimal(text string) float64 {
+	number, err := strconv.ParseFloat(decimalRe.FindString(strings.ReplaceAll(text, ",", ".")), 64)
+	if err != nil {
+		return 0
+	}
+
+	return number
+}
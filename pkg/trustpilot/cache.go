@@ -0,0 +1,206 @@
+package trustpilot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheTransport serves GET requests from the responses stored on disk, so the same pages aren't requested
+// again within the TTL, e.g. while developing the selectors. Only successful responses are stored, together with
+// their headers, which keep the ETag and Last-Modified validators to revalidate the expired responses.
+// The responses are stored per URL and request headers, as the session cookies and headers may change the page.
+type cacheTransport struct {
+	dir  string
+	ttl  time.Duration
+	next http.RoundTripper
+}
+
+// NewCacheTransport returns a round tripper which caches the successful GET responses of next in dir for ttl.
+// A nil next means http.DefaultTransport.
+func NewCacheTransport(dir string, ttl time.Duration, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &cacheTransport{dir: dir, ttl: ttl, next: next}
+}
+
+// WithCache caches the page responses in dir for ttl, see NewCacheTransport.
+func WithCache(dir string, ttl time.Duration) Option {
+	return func(s *Scraper) {
+		s.cacheDir = dir
+		s.cacheTTL = ttl
+	}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	path := t.path(req)
+	cached, fresh := t.load(path, req)
+	if cached != nil && fresh {
+		return cached, nil
+	}
+
+	// an expired response is revalidated with its validators, so an unchanged page isn't downloaded again
+	if cached != nil {
+		req = conditionalRequest(req, cached)
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && res.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			slog.Warn("Cannot refresh cached response", "url", req.URL.String(), "error", err)
+		}
+
+		return cached, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return res, nil
+	}
+
+	// the body is read in full to store it, so the response gets a copy of it
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	// a broken cache must not break the scraping
+	if err := t.store(path, res); err != nil {
+		slog.Warn("Cannot cache response", "url", req.URL.String(), "error", err)
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	return res, nil
+}
+
+// path returns the file of the cached response of the request, named by the hash of its URL and headers.
+// The validators added to revalidate the cached response don't change the page, so they are left out.
+func (t *cacheTransport) path(req *http.Request) string {
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		if key != "If-None-Match" && key != "If-Modified-Since" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	io.WriteString(hash, req.URL.String())
+	for _, key := range keys {
+		fmt.Fprintf(hash, "\n%s: %s", key, strings.Join(req.Header.Values(key), ", "))
+	}
+
+	return filepath.Join(t.dir, hex.EncodeToString(hash.Sum(nil)))
+}
+
+// evict removes the cached response of the request, e.g. a page which turned out to be a challenge.
+// It does nothing on a nil cache.
+func (t *cacheTransport) evict(req *http.Request) {
+	if t == nil || req == nil {
+		return
+	}
+
+	if err := os.Remove(t.path(req)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Cannot remove cached response", "url", req.URL.String(), "error", err)
+	}
+}
+
+// load reads the cached response and reports whether it's still within the TTL.
+// It returns nil when there is no cached response.
+func (t *cacheTransport) load(path string, req *http.Request) (*http.Response, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		return nil, false
+	}
+
+	return res, t.ttl <= 0 || time.Since(info.ModTime()) <= t.ttl
+}
+
+// conditionalRequest returns a copy of the request carrying the ETag and Last-Modified validators of the cached
+// response, so the server responds with 304 Not Modified when the page hasn't changed.
+func conditionalRequest(req *http.Request, cached *http.Response) *http.Request {
+	etag, lastModified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	return req
+}
+
+// store writes the response to a temporary file first, so a concurrent reader never sees a partial response.
+func (t *cacheTransport) store(path string, res *http.Response) error {
+	dump, err := httputil.DumpResponse(res, true)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(t.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(dump); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
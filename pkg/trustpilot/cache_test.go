@@ -0,0 +1,50 @@
+package trustpilot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestCacheDropsChallengePages(t *testing.T) {
+	site := newTestSite(t, 1)
+	site.challenges.Store(1)
+	dir := t.TempDir()
+
+	// without the TTL the cached challenge would be replayed forever
+	_, err := site.scraper(WithCache(dir, 0)).Reviews(context.Background(), testProduct)
+	if !errors.Is(err, ErrBlocked) {
+		t.Fatalf("got error %v, want %v", err, ErrBlocked)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("the challenge page is cached in %d files", len(entries))
+	}
+
+	productReviews, err := site.scraper(WithCache(dir, 0)).Reviews(context.Background(), testProduct)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(productReviews.Reviews) != 1 {
+		t.Errorf("got %d reviews, want 1", len(productReviews.Reviews))
+	}
+}
+
+func TestCacheKeyIncludesHeaders(t *testing.T) {
+	site := newTestSite(t, 1)
+	dir := t.TempDir()
+
+	for _, session := range []string{"alice", "bob", "alice"} {
+		scraper := site.scraper(WithCache(dir, 0), WithHeaders(http.Header{"X-Session": {session}}))
+		if _, err := scraper.Reviews(context.Background(), testProduct); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if hits := site.hits.Load(); hits != 2 {
+		t.Errorf("requested the page %d times, want once per session", hits)
+	}
+}
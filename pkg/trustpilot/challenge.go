@@ -0,0 +1,99 @@
+package trustpilot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxChallengeBodySize limits how much of an error response body is read to look for the challenge markers.
+const maxChallengeBodySize = 1 << 20
+
+// ErrBlocked is returned when Trustpilot, or the CDN in front of it, serves a bot challenge instead of the page.
+var ErrBlocked = errors.New("blocked by a bot challenge")
+
+// ChallengeMarkers describe how a bot challenge page is recognized. A page matching any of the markers is a challenge.
+type ChallengeMarkers struct {
+	// Titles are substrings of the page title, matched case-insensitively.
+	Titles []string
+	// Selectors are goquery selectors of the elements present on the challenge page only.
+	Selectors []string
+}
+
+// DefaultChallengeMarkers recognize the Cloudflare challenge pages.
+var DefaultChallengeMarkers = ChallengeMarkers{
+	Titles: []string{"Just a moment", "Attention Required", "Access denied"},
+	Selectors: []string{
+		"#cf-challenge-running",
+		"#challenge-form",
+		"#challenge-running",
+		"[class^='cf-challenge'], [class*=' cf-challenge']",
+		"script[src*='/cdn-cgi/challenge-platform/']",
+	},
+}
+
+// WithChallengeMarkers replaces the markers used to recognize bot challenge pages.
+func WithChallengeMarkers(markers ChallengeMarkers) Option {
+	return func(s *Scraper) {
+		s.challengeMarkers = markers
+	}
+}
+
+// matches reports whether the document is a challenge page.
+func (m ChallengeMarkers) matches(doc *goquery.Document) bool {
+	title := strings.ToLower(doc.Find("title").First().Text())
+	for _, marker := range m.Titles {
+		if strings.Contains(title, strings.ToLower(marker)) {
+			return true
+		}
+	}
+
+	for _, selector := range m.Selectors {
+		if doc.Find(selector).Length() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkChallengeResponse detects a challenge served with an error status, Cloudflare uses 403 and 503 for them.
+// The body is consumed, so it must be called only for the responses which are discarded anyway.
+func (s *Scraper) checkChallengeResponse(res *http.Response, pageURL string) error {
+	if strings.EqualFold(res.Header.Get("Cf-Mitigated"), "challenge") {
+		return blockedError(pageURL)
+	}
+
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusServiceUnavailable {
+		return nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(res.Body, maxChallengeBodySize))
+	if err != nil {
+		return nil
+	}
+
+	if s.challengeMarkers.matches(doc) {
+		return blockedError(pageURL)
+	}
+
+	return nil
+}
+
+// checkChallengeDocument detects a challenge served with a successful status.
+func (s *Scraper) checkChallengeDocument(doc *goquery.Document, pageURL string) error {
+	if s.challengeMarkers.matches(doc) {
+		return blockedError(pageURL)
+	}
+
+	return nil
+}
+
+func blockedError(pageURL string) error {
+	return fmt.Errorf("%w at %s, Trustpilot refused to serve the page to the scraper: "+
+		"slow down with a lower rate limit, change the user agent or use a proxy", ErrBlocked, pageURL)
+}
@@ -0,0 +1,175 @@
+package trustpilot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// checkpointInterval is how often the checkpoint is saved while scraping. Every save writes all reviews
+// collected so far, so saving after every page would slow down the large scrapes.
+const checkpointInterval = 10 * time.Second
+
+// WithCheckpoint makes Reviews save the scraped pages and the reviews collected so far into a checkpoint file
+// per product in dir, so a crashed or interrupted scraping doesn't lose its progress. The checkpoint is removed
+// once the product is scraped without failed pages.
+// With resume, an existing checkpoint is loaded and its pages aren't scraped again, only the first page is requested
+// anyway to discover the pages. Resume with the same filters, as the loaded reviews already passed the former ones.
+func WithCheckpoint(dir string, resume bool) Option {
+	return func(s *Scraper) {
+		s.checkpointDir = dir
+		s.resume = resume
+	}
+}
+
+// checkpoint is the progress of the product scraping. The pages are recorded only once all their reviews
+// are handled, so a resumed scraping never misses the reviews of a recorded page.
+// Only the collector goroutine records the progress, while the page scraping goroutines read the resumed pages,
+// which are loaded before the scraping starts and never change afterwards.
+type checkpoint struct {
+	Product   string    `json:"product"`
+	Pages     []int     `json:"pages"`
+	Reviews   []*Review `json:"reviews"`
+	Filtered  int       `json:"filtered"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	path    string
+	resumed map[int]bool
+	savedAt time.Time
+}
+
+// openCheckpoint returns the checkpoint of the product, loaded from dir when resuming and empty otherwise.
+func (s *Scraper) openCheckpoint(product string) (*checkpoint, error) {
+	cp := &checkpoint{
+		Product: product,
+		Reviews: make([]*Review, 0),
+		path:    filepath.Join(s.checkpointDir, url.PathEscape(product)+".json"),
+		resumed: make(map[int]bool),
+		savedAt: time.Now(),
+	}
+
+	if !s.resume {
+		return cp, nil
+	}
+
+	data, err := os.ReadFile(cp.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cp, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("decode checkpoint %s: %w", cp.path, err)
+	}
+
+	if cp.Product != product {
+		return nil, fmt.Errorf("checkpoint %s belongs to product %q, not %q", cp.path, cp.Product, product)
+	}
+
+	for _, page := range cp.Pages {
+		cp.resumed[page] = true
+	}
+
+	s.logger.Info("Resuming from checkpoint", "product", product, "pages", len(cp.Pages), "reviews", len(cp.Reviews))
+
+	return cp, nil
+}
+
+// skips reports whether the page was scraped before the checkpoint.
+func (cp *checkpoint) skips(page int) bool {
+	return cp != nil && cp.resumed[page]
+}
+
+// add records the handled review.
+func (cp *checkpoint) add(review *Review) {
+	if cp == nil {
+		return
+	}
+
+	cp.Reviews = append(cp.Reviews, review)
+}
+
+// drop counts the review dropped by the filters.
+func (cp *checkpoint) drop() {
+	if cp == nil {
+		return
+	}
+
+	cp.Filtered++
+}
+
+// pageDone records the page whose reviews are all handled and saves the checkpoint once the interval passed.
+func (cp *checkpoint) pageDone(page int) error {
+	if cp == nil {
+		return nil
+	}
+
+	cp.Pages = append(cp.Pages, page)
+
+	if time.Since(cp.savedAt) < checkpointInterval {
+		return nil
+	}
+
+	return cp.save()
+}
+
+// save writes the checkpoint into a temporary file and renames it, so a crash in the middle of the write
+// never leaves a truncated checkpoint behind.
+func (cp *checkpoint) save() error {
+	sort.Ints(cp.Pages)
+	cp.UpdatedAt = time.Now().UTC()
+	cp.savedAt = time.Now()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(cp.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), cp.path)
+}
+
+// closeCheckpoint removes the checkpoint once the product is scraped completely, otherwise it's saved
+// with the latest progress.
+func (s *Scraper) closeCheckpoint(cp *checkpoint, scrapeErr error) error {
+	if scrapeErr != nil {
+		s.logger.Info("Saving checkpoint to resume from", "product", cp.Product, "pages", len(cp.Pages), "path", cp.path)
+
+		return cp.save()
+	}
+
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package trustpilot
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// pageCutoff tracks the first page which reached the reviews not newer than the cutoff date. Trustpilot lists
+// the newest reviews first, so the pages after it contain only older reviews and are not scraped.
+type pageCutoff struct {
+	newerThan time.Time
+	page      atomic.Int64
+}
+
+func newPageCutoff(newerThan time.Time) *pageCutoff {
+	cutoff := &pageCutoff{newerThan: newerThan}
+	cutoff.page.Store(math.MaxInt64)
+
+	return cutoff
+}
+
+// beyond reports whether the page goes after the cutoff page and doesn't need to be scraped.
+func (c *pageCutoff) beyond(page int) bool {
+	return int64(page) > c.page.Load()
+}
+
+// check moves the cutoff to the page if it contains a review not newer than the cutoff date.
+func (c *pageCutoff) check(page int, reviews []*Review) {
+	if c.newerThan.IsZero() {
+		return
+	}
+
+	for _, review := range reviews {
+		if review.ParsedDate.IsZero() || review.ParsedDate.After(c.newerThan) {
+			continue
+		}
+
+		// pages are scraped in parallel, so we keep the lowest page that reached the cutoff
+		for {
+			current := c.page.Load()
+			if int64(page) >= current || c.page.CompareAndSwap(current, int64(page)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package trustpilot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var relativeDateRe = regexp.MustCompile(`(?i)\b(\d+|an?)\s+(minute|hour|day|week|month|year)s?\s+ago\b`)
+
+// absoluteDateLayouts are the formats of the human-readable review dates, like "Apr 5, 2024".
+var absoluteDateLayouts = []string{
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"2006-01-02",
+}
+
+// parseDateText parses the human-readable date of the review card, shown when the datetime attribute is absent.
+// Relative dates, like "2 days ago", are resolved against now. It returns false when the text is not a known date.
+func parseDateText(text string, now time.Time) (time.Time, bool) {
+	text = strings.TrimSpace(text)
+	// updated reviews are prefixed, like "Updated Apr 5, 2024"
+	text = strings.TrimSpace(strings.TrimPrefix(text, "Updated"))
+
+	switch strings.ToLower(text) {
+	case "":
+		return time.Time{}, false
+	case "just now", "today":
+		return now.UTC(), true
+	case "yesterday":
+		return now.UTC().AddDate(0, 0, -1), true
+	}
+
+	if match := relativeDateRe.FindStringSubmatch(text); match != nil {
+		n := 1
+		if number, err := strconv.Atoi(match[1]); err == nil {
+			n = number
+		}
+
+		now = now.UTC()
+		switch strings.ToLower(match[2]) {
+		case "minute":
+			return now.Add(-time.Duration(n) * time.Minute), true
+		case "hour":
+			return now.Add(-time.Duration(n) * time.Hour), true
+		case "day":
+			return now.AddDate(0, 0, -n), true
+		case "week":
+			return now.AddDate(0, 0, -7*n), true
+		case "month":
+			return now.AddDate(0, -n, 0), true
+		case "year":
+			return now.AddDate(-n, 0, 0), true
+		}
+	}
+
+	for _, layout := range absoluteDateLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed.UTC(), true
+		}
+	}
+
+	return time.Time{}, false
+}
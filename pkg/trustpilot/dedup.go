@@ -0,0 +1,40 @@
+package trustpilot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DeduplicateReviews drops the repeated reviews, keeping the first occurrence of each one,
+// and returns the unique reviews with the number of dropped duplicates.
+func DeduplicateReviews(reviews []*Review) ([]*Review, int) {
+	seen := make(map[string]struct{}, len(reviews))
+	unique := make([]*Review, 0, len(reviews))
+	for _, review := range reviews {
+		key := review.Key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		unique = append(unique, review)
+	}
+
+	return unique, len(reviews) - len(unique)
+}
+
+// Key identifies the review by its ID, or by a hash of the text, date and author when the ID is absent.
+func (review *Review) Key() string {
+	if review.ID != "" {
+		return review.ID
+	}
+
+	hash := sha256.New()
+	for _, field := range []string{review.Text, review.Date, review.Author} {
+		hash.Write([]byte(field))
+		// separate the fields, so moving characters between them gives a different hash
+		hash.Write([]byte{0})
+	}
+
+	return "hash:" + hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,18 @@
+package trustpilot
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Download requests the file, e.g. one of the Review.Images, and copies it into w. It goes through the same
+// rate limit and retries as the page requests, while the headers of WithHeaders are sent only to the Trustpilot
+// domain, as the images are usually served by another host.
+func (s *Scraper) Download(ctx context.Context, fileURL string, w io.Writer) error {
+	return s.fetchBody(ctx, fileURL, func(res *http.Response) error {
+		_, err := io.Copy(w, res.Body)
+
+		return err
+	})
+}
@@ -0,0 +1,59 @@
+package trustpilot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNoReviews is returned when no reviews are found where they are expected.
+var ErrNoReviews = errors.New("no reviews found")
+
+// PagesError is returned when some review pages couldn't be scraped. The reviews of the other pages
+// are still collected, so the result is incomplete rather than missing.
+type PagesError struct {
+	// Errors maps the number of every failed page to the reason of the failure.
+	Errors map[int]error
+}
+
+func (e *PagesError) Error() string {
+	pages := e.Pages()
+	messages := make([]string, 0, len(pages))
+	for _, page := range pages {
+		messages = append(messages, fmt.Sprintf("page %d: %s", page, e.Errors[page]))
+	}
+
+	return fmt.Sprintf("cannot scrape %d pages: %s", len(pages), strings.Join(messages, "; "))
+}
+
+// Pages returns the sorted numbers of the failed pages.
+func (e *PagesError) Pages() []int {
+	pages := make([]int, 0, len(e.Errors))
+	for page := range e.Errors {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	return pages
+}
+
+// IsPartial reports whether the error still comes with the reviews collected before it happened:
+// some pages failed or the scraping was cancelled.
+func IsPartial(err error) bool {
+	var pagesErr *PagesError
+
+	return errors.As(err, &pagesErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// StatusError is returned when Trustpilot responds with an unexpected HTTP status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request to %s responded with status %s", e.URL, e.Status)
+}
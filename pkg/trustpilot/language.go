@@ -0,0 +1,26 @@
+package trustpilot
+
+import (
+	"strings"
+
+	"github.com/abadojack/whatlanggo"
+)
+
+// WithLanguageDetection enables the detection of the language of every review, stored into Review.Language.
+// It's disabled by default, as it costs CPU time for every review.
+func WithLanguageDetection(enabled bool) Option {
+	return func(s *Scraper) {
+		s.detectLanguage = enabled
+	}
+}
+
+// detectLanguage returns the ISO 639-1 code of the language of the review title and text,
+// or an empty string when there is no text or the language is unknown.
+func detectLanguage(review *Review) string {
+	text := strings.TrimSpace(review.Title + "\n" + review.Text)
+	if text == "" {
+		return ""
+	}
+
+	return whatlanggo.DetectLang(text).Iso6391()
+}
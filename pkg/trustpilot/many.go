@@ -0,0 +1,117 @@
+package trustpilot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithProductConcurrency sets how many products ScrapeMany scrapes in parallel. Values below 1 are treated as 1.
+// Every product still scrapes its pages in parallel, see WithConcurrency. The parallel products report to the same
+// Progress, so it has to tell them apart or be left unset.
+func WithProductConcurrency(n int) Option {
+	return func(s *Scraper) {
+		if n < 1 {
+			n = 1
+		}
+
+		s.productConcurrency = n
+	}
+}
+
+// ProductsError is returned by ScrapeMany when some products couldn't be scraped completely.
+type ProductsError struct {
+	// Errors maps every failed product to the error of its scraping, see IsPartial for the incomplete ones.
+	Errors map[string]error
+}
+
+func (e *ProductsError) Error() string {
+	products := make([]string, 0, len(e.Errors))
+	for product := range e.Errors {
+		products = append(products, product)
+	}
+	sort.Strings(products)
+
+	messages := make([]string, 0, len(products))
+	for _, product := range products {
+		messages = append(messages, fmt.Sprintf("%s: %s", product, e.Errors[product]))
+	}
+
+	return fmt.Sprintf("cannot scrape %d products: %s", len(products), strings.Join(messages, "; "))
+}
+
+// ScrapeMany scrapes the reviews of the products in parallel, see WithProductConcurrency. All products share
+// the rate limit of the scraper, so scraping them together makes no more requests per second than one by one.
+// The result maps every product to its reviews, including the incomplete ones, and the products which failed
+// or are incomplete are reported with a *ProductsError.
+func (s *Scraper) ScrapeMany(ctx context.Context, products []string) (map[string]*ProductReviews, error) {
+	var (
+		mu      sync.Mutex
+		results = make(map[string]*ProductReviews, len(products))
+	)
+
+	errs := s.EachProduct(ctx, products, func(ctx context.Context, product string) error {
+		productReviews, err := s.Reviews(ctx, product)
+		if productReviews != nil {
+			mu.Lock()
+			results[product] = productReviews
+			mu.Unlock()
+		}
+
+		return err
+	})
+
+	if len(errs) > 0 {
+		return results, &ProductsError{Errors: errs}
+	}
+
+	return results, nil
+}
+
+// EachProduct calls scrape for every product, running as many of them in parallel as WithProductConcurrency allows,
+// e.g. to handle the products in another way than ScrapeMany does. It doesn't stop on the failed products
+// and returns the errors of scrape by product. Once ctx is cancelled, the products not started yet fail with
+// the context error without calling scrape.
+func (s *Scraper) EachProduct(ctx context.Context, products []string, scrape func(ctx context.Context, product string) error) map[string]error {
+	var (
+		mu   sync.Mutex
+		errs = make(map[string]error)
+	)
+	fail := func(product string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs[product] = err
+	}
+
+	jobs := make(chan string)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < s.productConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for product := range jobs {
+				if ctx.Err() != nil {
+					fail(product, ctx.Err())
+
+					continue
+				}
+
+				if err := scrape(ctx, product); err != nil {
+					fail(product, err)
+				}
+			}
+		}()
+	}
+
+	for _, product := range products {
+		jobs <- product
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,80 @@
+package trustpilot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrUnexpectedPage is returned when the response doesn't look like a Trustpilot review page, e.g. it's an error page
+// or a truncated body, so it's not mistaken for a page without reviews.
+var ErrUnexpectedPage = errors.New("unexpected page markup")
+
+// bodySnippetSize is how much of an unexpected body is included into the error, enough to recognize
+// a JSON error or a plain text message.
+const bodySnippetSize = 200
+
+// pageMarkers are the selectors of elements present on every Trustpilot review page, even without reviews.
+var pageMarkers = []string{
+	"script#__NEXT_DATA__",
+	"[class^='styles_businessUnitHeader__'], [class*=' styles_businessUnitHeader__']",
+	"[class^='styles_businessInformation__'], [class*=' styles_businessInformation__']",
+	"[class^='styles_reviewCard__'], [class*=' styles_reviewCard__']",
+	"[class^='styles_cardWrapper__'], [class*=' styles_cardWrapper__']",
+}
+
+// emptyStateMarkers are the selectors of the "no reviews yet" placeholder shown for businesses without reviews.
+var emptyStateMarkers = []string{
+	"[class^='styles_emptyState__'], [class*=' styles_emptyState__']",
+	"[data-reviews-empty-state]",
+	"[data-no-reviews-typography]",
+}
+
+// hasEmptyState reports whether the page shows the placeholder of a business without reviews.
+func hasEmptyState(doc *goquery.Document) bool {
+	for _, marker := range emptyStateMarkers {
+		if doc.Find(marker).Length() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMarkup makes sure the document contains recognizable Trustpilot markup.
+func checkMarkup(doc *goquery.Document, pageURL string) error {
+	for _, marker := range pageMarkers {
+		if doc.Find(marker).Length() > 0 {
+			return nil
+		}
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+
+	return fmt.Errorf("%w at %s (title %q)", ErrUnexpectedPage, pageURL, title)
+}
+
+// checkContentType makes sure the response is HTML before it's parsed, as goquery makes a document out of anything,
+// e.g. a JSON error returned with 200 OK. A response without the header is parsed anyway.
+func checkContentType(res *http.Response, pageURL string) error {
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+		return nil
+	}
+
+	// the body is drained by the caller anyway, so reading its start costs nothing
+	snippet, _ := io.ReadAll(io.LimitReader(res.Body, bodySnippetSize))
+
+	return fmt.Errorf("%w at %s: content type %q instead of HTML, body starts with %q",
+		ErrUnexpectedPage, pageURL, contentType, snippet)
+}
@@ -0,0 +1,85 @@
+package trustpilot
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "trustpilot_scraper"
+
+// Metrics are the Prometheus metrics of the scraping. A nil *Metrics records nothing.
+type Metrics struct {
+	pagesFetched     prometheus.Counter
+	reviewsCollected prometheus.Counter
+	requestErrors    *prometheus.CounterVec
+	requestDuration  prometheus.Histogram
+}
+
+// NewMetrics creates the scraping metrics and registers them on the registerer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		pagesFetched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "pages_fetched_total",
+			Help:      "Number of review pages fetched and parsed.",
+		}),
+		reviewsCollected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "reviews_collected_total",
+			Help:      "Number of reviews passed the filters and handed to the caller.",
+		}),
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "request_errors_total",
+			Help:      "Number of failed request attempts by reason.",
+		}, []string{"reason"}),
+		requestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the request attempts until the response headers.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+
+	collectors := []prometheus.Collector{m.pagesFetched, m.reviewsCollected, m.requestErrors, m.requestDuration}
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// WithMetrics records the scraping metrics, see NewMetrics.
+func WithMetrics(metrics *Metrics) Option {
+	return func(s *Scraper) {
+		s.metrics = metrics
+	}
+}
+
+func (m *Metrics) pageFetched() {
+	if m != nil {
+		m.pagesFetched.Inc()
+	}
+}
+
+func (m *Metrics) reviewCollected() {
+	if m != nil {
+		m.reviewsCollected.Inc()
+	}
+}
+
+// requestFailed counts the failed attempt, reason is like "network" or the status code.
+func (m *Metrics) requestFailed(reason string) {
+	if m != nil {
+		m.requestErrors.WithLabelValues(reason).Inc()
+	}
+}
+
+func (m *Metrics) requestDone(d time.Duration) {
+	if m != nil {
+		m.requestDuration.Observe(d.Seconds())
+	}
+}
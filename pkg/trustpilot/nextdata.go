@@ -0,0 +1,127 @@
+package trustpilot
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// nextData is the part of the __NEXT_DATA__ JSON of the review page we are interested in.
+// Trustpilot is a Next.js app, so the data is far more stable than the CSS class names of the markup.
+type nextData struct {
+	Props struct {
+		PageProps struct {
+			Reviews []nextDataReview `json:"reviews"`
+			// Review is set on the permalink page of a single review
+			Review *nextDataReview `json:"review"`
+		} `json:"pageProps"`
+	} `json:"props"`
+}
+
+type nextDataReview struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Text   string `json:"text"`
+	Rating int    `json:"rating"`
+	Likes  int    `json:"likes"`
+	Dates  struct {
+		PublishedDate   string `json:"publishedDate"`
+		ExperiencedDate string `json:"experiencedDate"`
+	} `json:"dates"`
+	Consumer struct {
+		DisplayName     string `json:"displayName"`
+		CountryCode     string `json:"countryCode"`
+		NumberOfReviews int    `json:"numberOfReviews"`
+	} `json:"consumer"`
+	Labels struct {
+		Verification struct {
+			IsVerified         bool   `json:"isVerified"`
+			VerificationSource string `json:"verificationSource"`
+			VerificationLevel  string `json:"verificationLevel"`
+		} `json:"verification"`
+	} `json:"labels"`
+	Reply *struct {
+		Message       string `json:"message"`
+		PublishedDate string `json:"publishedDate"`
+	} `json:"reply"`
+}
+
+// parseNextDataReviews extracts the reviews from the __NEXT_DATA__ script of the page.
+// It returns false when the script is missing or cannot be decoded, so the caller can fall back to the markup.
+func parseNextDataReviews(doc *goquery.Document, productURL string) ([]*Review, bool) {
+	script := doc.Find("script#__NEXT_DATA__").First()
+	if script.Length() == 0 {
+		return nil, false
+	}
+
+	data := &nextData{}
+	if err := json.Unmarshal([]byte(script.Text()), data); err != nil {
+		slog.Warn("Cannot decode __NEXT_DATA__", "url", productURL, "error", err)
+
+		return nil, false
+	}
+
+	raws := data.Props.PageProps.Reviews
+	if data.Props.PageProps.Review != nil {
+		raws = append(raws, *data.Props.PageProps.Review)
+	}
+
+	reviews := make([]*Review, 0, len(raws))
+	for _, raw := range raws {
+		review := &Review{
+			ID:                   raw.ID,
+			Text:                 raw.Text,
+			Date:                 raw.Dates.PublishedDate,
+			ParsedDate:           parseDate(raw.Dates.PublishedDate),
+			RatingText:           fmt.Sprintf("Rated %d out of 5 stars", raw.Rating),
+			Stars:                raw.Rating,
+			Title:                raw.Title,
+			Author:               raw.Consumer.DisplayName,
+			Country:              strings.ToUpper(raw.Consumer.CountryCode),
+			AuthorReviewCount:    raw.Consumer.NumberOfReviews,
+			Verified:             raw.Labels.Verification.IsVerified,
+			Useful:               raw.Likes,
+			Invited:              raw.Labels.Verification.VerificationSource == "invitation" || raw.Labels.Verification.VerificationLevel == "invited",
+			ExperienceDate:       raw.Dates.ExperiencedDate,
+			ParsedExperienceDate: parseDate(raw.Dates.ExperiencedDate),
+		}
+
+		if raw.ID != "" {
+			review.Link = reviewLink(productURL, "/reviews/"+raw.ID)
+		}
+
+		if raw.Reply != nil {
+			review.Reply = &Reply{
+				Text:       raw.Reply.Message,
+				Date:       raw.Reply.PublishedDate,
+				ParsedDate: parseDate(raw.Reply.PublishedDate),
+			}
+			review.ReplyLatency = replyLatency(review.ParsedDate, review.Reply)
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, true
+}
+
+// addCardImages fills the images of the reviews from the markup of their cards, matched by the review ID,
+// as the __NEXT_DATA__ reviews don't carry the images uploaded by the reviewers.
+func addCardImages(doc *goquery.Document, reviews []*Review, productURL string, sel *Selectors) {
+	byID := make(map[string]*Review, len(reviews))
+	for _, review := range reviews {
+		if review.ID != "" {
+			byID[review.ID] = review
+		}
+	}
+
+	doc.Find(sel.Card).Each(func(i int, card *goquery.Selection) {
+		link, _ := card.Find("a[data-review-title-typography]").Attr("href")
+		if review, ok := byID[parseReviewID(card, link)]; ok && len(review.Images) == 0 {
+			review.Images = parseImages(card, sel.Images, productURL)
+		}
+	})
+}
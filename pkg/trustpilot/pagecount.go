@@ -0,0 +1,43 @@
+package trustpilot
+
+import (
+	"context"
+)
+
+// PageCount describes how many reviews a product has, as seen on its first review page.
+type PageCount struct {
+	// Pages is the number of review pages, or zero when the pagination doesn't show the last page.
+	Pages int
+	// ReviewsPerPage is the number of reviews on the first page.
+	ReviewsPerPage int
+	// EstimatedReviews assumes every page is as full as the first one, so it's an upper bound.
+	EstimatedReviews int
+}
+
+// CountPages requests only the first review page of the product and reports the number of pages,
+// so the cost of a full scraping can be estimated beforehand. Filters and the page range are not applied.
+func (s *Scraper) CountPages(ctx context.Context, product string) (*PageCount, error) {
+	product, err := NormalizeProduct(product)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, productURL, err := s.fetchProductPage(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+
+	count := &PageCount{ReviewsPerPage: len(parsePageReviews(doc, productURL, &s.selectors, false))}
+
+	lastPage, method := detectLastPage(doc)
+	if _, hasNext := nextPageURL(doc, productURL); method == "" && hasNext {
+		s.logger.Debug("Cannot detect number of pages", "product", product)
+
+		return count, nil
+	}
+
+	count.Pages = lastPage
+	count.EstimatedReviews = lastPage * count.ReviewsPerPage
+
+	return count, nil
+}
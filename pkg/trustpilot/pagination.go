@@ -0,0 +1,186 @@
+package trustpilot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Pagination defines how the review pages after the first one are discovered.
+type Pagination string
+
+const (
+	// PaginationParallel detects the number of pages on the first page and scrapes all pages in parallel.
+	PaginationParallel Pagination = "parallel"
+	// PaginationSequential follows the "Next" link from page to page, for layouts without the last page link.
+	PaginationSequential Pagination = "sequential"
+)
+
+const defaultMaxPages = 1000
+
+// ParsePagination checks that the pagination mode is one of the supported ones.
+func ParsePagination(mode string) (Pagination, error) {
+	switch Pagination(mode) {
+	case PaginationParallel, PaginationSequential:
+		return Pagination(mode), nil
+	default:
+		return "", fmt.Errorf("unsupported pagination %q, expected one of: %s, %s", mode, PaginationParallel, PaginationSequential)
+	}
+}
+
+// nextPageURL returns the absolute URL of the "Next" pagination link, or false when there is no next page.
+func nextPageURL(doc *goquery.Document, pageURL string) (string, bool) {
+	href, exists := doc.Find("a[name='pagination-button-next']").First().Attr("href")
+	if !exists || href == "" {
+		return "", false
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+
+	next, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	return base.ResolveReference(next).String(), true
+}
+
+// scrapePagesSequentially follows the "Next" links starting from the first page document and sends the reviews
+// of every next page to the channel. It stops at the last page, at the max pages cap or on the first failure,
+// as the next page can't be discovered without the current one. The pages of the checkpoint are still requested
+// to follow their links, but their reviews are skipped.
+func (s *Scraper) scrapePagesSequentially(
+	ctx context.Context,
+	pages chan<- pageBatch,
+	name string,
+	productURL string,
+	firstPage *goquery.Document,
+	cutoff *pageCutoff,
+	cp *checkpoint,
+	onPageErr func(page int, err error),
+) {
+	// visited URLs guard against the pagination links going in circles
+	visited := map[string]struct{}{productURL: {}}
+
+	doc, pageURL := firstPage, productURL
+	for page := 2; ; page++ {
+		nextURL, ok := nextPageURL(doc, pageURL)
+		if !ok {
+			return
+		}
+
+		if _, seen := visited[nextURL]; seen {
+			s.logger.Warn("Next page link leads to an already scraped page, stopping", "product", name, "page", page-1)
+
+			return
+		}
+
+		if page > s.maxPages || (s.endPage > 0 && page > s.endPage) || cutoff.beyond(page) || ctx.Err() != nil {
+			return
+		}
+
+		s.logger.Debug("Start scraping page", "product", name, "page", page)
+		s.progress.PageStarted(page)
+
+		nextDoc, err := s.fetchDocument(ctx, nextURL)
+		if err != nil {
+			s.logPageErr(ctx, name, page, err)
+			onPageErr(page, err)
+
+			return
+		}
+
+		visited[nextURL] = struct{}{}
+		doc, pageURL = nextDoc, nextURL
+		// the next link is relative to the page we ended up on after the redirects
+		if nextDoc.Url != nil {
+			pageURL = nextDoc.Url.String()
+		}
+
+		pageReviews := s.parsePage(doc, productURL)
+		s.progress.PageDone(page, len(pageReviews))
+		cutoff.check(page, pageReviews)
+
+		if page < s.startPage || cp.skips(page) {
+			continue
+		}
+
+		pages <- pageBatch{page: page, reviews: pageReviews}
+	}
+}
+
+// WithPageOrder makes the parallel pagination hand the reviews over in the page order rather than as the pages
+// arrive. The pages scraped ahead of time are held back until the earlier ones are done, so it costs some memory.
+func WithPageOrder(ordered bool) Option {
+	return func(s *Scraper) {
+		s.pageOrder = ordered
+	}
+}
+
+// pageBatch is the reviews of a scraped page. The reviews of a page are handed over together,
+// so the collector knows when all of them are handled.
+type pageBatch struct {
+	page    int
+	reviews []*Review
+}
+
+// pageOrderer sends the pages to the channel in the page order.
+type pageOrderer struct {
+	mu      sync.Mutex
+	next    int
+	pending map[int]*pageBatch
+	pages   chan<- pageBatch
+}
+
+func newPageOrderer(firstPage int, pages chan<- pageBatch) *pageOrderer {
+	return &pageOrderer{next: firstPage, pending: make(map[int]*pageBatch), pages: pages}
+}
+
+// done hands over the scraped page, nil for failed or skipped pages, and sends every page
+// which has no earlier pages pending.
+func (o *pageOrderer) done(page int, batch *pageBatch) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pending[page] = batch
+	for {
+		next, ok := o.pending[o.next]
+		if !ok {
+			return
+		}
+
+		delete(o.pending, o.next)
+		o.next++
+
+		if next != nil {
+			o.pages <- *next
+		}
+	}
+}
+
+// flush sends the pages left behind a page which was never scraped, e.g. after a cancellation.
+func (o *pageOrderer) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pages := make([]int, 0, len(o.pending))
+	for page := range o.pending {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	for _, page := range pages {
+		if batch := o.pending[page]; batch != nil {
+			o.pages <- *batch
+		}
+	}
+
+	o.pending = make(map[int]*pageBatch)
+}
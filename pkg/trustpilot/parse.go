@@ -0,0 +1,377 @@
+package trustpilot
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// experienceDateLayout is the format of the "Date of experience:" line, like "May 03, 2023"
+const experienceDateLayout = "January 02, 2006"
+
+var numberRe = regexp.MustCompile(`\d+`)
+
+var starsImageRe = regexp.MustCompile(`stars-(\d)[^/]*$`)
+
+// extractReviewFunc returns a goquery Each callback which sends every review card of the selection to the channel.
+// With includeHTML, the markup of the card is kept in the review.
+func extractReviewFunc(reviews chan<- *Review, productURL string, sel *Selectors, includeHTML bool) func(i int, s *goquery.Selection) {
+	return func(i int, s *goquery.Selection) {
+		review, ok := parseReviewCard(s, productURL, sel)
+		if !ok {
+			return
+		}
+
+		if includeHTML {
+			html, err := s.Html()
+			if err != nil {
+				slog.Debug("Cannot render review card", "url", productURL, "error", err)
+			}
+			review.RawHTML = html
+		}
+
+		reviews <- review
+	}
+}
+
+// parseReviewCard extracts the review from the selection. It reports false when the selection is not a review card.
+func parseReviewCard(s *goquery.Selection, productURL string, sel *Selectors) (*Review, bool) {
+	if !s.Is(sel.Card) && !isReviewCard(s) {
+		return nil, false
+	}
+
+	// extract review data
+	dateOfPost := parseCardDate(s, sel.Date)
+	parsedDate := parseDate(dateOfPost)
+	textOfReview := s.Find(sel.Text).Text()
+
+	title := s.Find(sel.Title).Text()
+	// the name element is missing for some reviews, then the author stays empty
+	author := s.Find(sel.Author).First().Text()
+	authorReviewCount := parseFirstNumber(s.Find("[data-consumer-reviews-count-typography]").First().Text())
+	experienceDate := parseExperienceDate(s)
+	verified := isVerified(s)
+	invited := isInvited(s)
+	useful := parseUseful(s)
+	images := parseImages(s, sel.Images, productURL)
+	reply := parseReply(s, sel.Reply)
+	country := strings.ToUpper(strings.TrimSpace(s.Find("span[data-consumer-country-typography]").First().Text()))
+	link, _ := s.Find("a[data-review-title-typography]").Attr("href")
+	id := parseReviewID(s, link)
+	link = reviewLink(productURL, link)
+
+	// we don't transform the data in place, as we want to keep the original data for future analysis
+	rating, stars := parseRating(s, sel.Rating)
+
+	return &Review{
+		ID:                   id,
+		Text:                 textOfReview,
+		Date:                 dateOfPost,
+		ParsedDate:           parsedDate,
+		RatingText:           rating,
+		Stars:                stars,
+		Title:                title,
+		Author:               author,
+		Country:              country,
+		AuthorReviewCount:    authorReviewCount,
+		Verified:             verified,
+		Invited:              invited,
+		Useful:               useful,
+		Images:               images,
+		Reply:                reply,
+		ReplyLatency:         replyLatency(parsedDate, reply),
+		ExperienceDate:       experienceDate,
+		ParsedExperienceDate: parseExperienceDateTime(experienceDate),
+		Link:                 link,
+	}, true
+}
+
+// isReviewCard validates if the element is a review card and a card wrapper
+// (to avoid processing other divs, like advertisement).
+func isReviewCard(s *goquery.Selection) bool {
+	classes, exists := s.Attr("class")
+	if !exists {
+		return false
+	}
+
+	isReviewCard := false
+	isCardWrapper := false
+
+	for _, class := range strings.Split(classes, " ") {
+		if strings.HasPrefix(class, "styles_reviewCard__") {
+			isReviewCard = true
+		}
+
+		if strings.HasPrefix(class, "styles_cardWrapper__") {
+			isCardWrapper = true
+		}
+	}
+
+	return isReviewCard && isCardWrapper
+}
+
+// reviewLink builds the review permalink from the href of the review card. The href is resolved against the page URL,
+// so absolute, root-relative and relative hrefs all produce a valid URL.
+func reviewLink(productURL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	base, err := url.Parse(productURL)
+	if err != nil {
+		return href
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		slog.Debug("Cannot parse review link", "href", href, "error", err)
+
+		return ""
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// parseReviewID extracts the Trustpilot review ID. It prefers the ID embedded in the review permalink,
+// like "/reviews/645a1b2c3d4e5f6a7b8c9d0e", and falls back to the review ID data attribute of the card.
+func parseReviewID(s *goquery.Selection, link string) string {
+	if path, _, _ := strings.Cut(link, "?"); strings.Contains(path, "/reviews/") {
+		if id := path[strings.LastIndex(path, "/")+1:]; id != "" {
+			return id
+		}
+	}
+
+	if id, exists := s.Attr("data-review-id"); exists {
+		return id
+	}
+
+	return s.Find("[data-review-id]").First().AttrOr("data-review-id", "")
+}
+
+// parseReply extracts the business reply from the review card. It returns nil when there is no reply.
+func parseReply(s *goquery.Selection, selector string) *Reply {
+	replyText := s.Find(selector).First()
+	if replyText.Length() == 0 {
+		return nil
+	}
+
+	// the reply date lives in the header of the reply block, so we go up from the text until we meet it.
+	// We stop before the card itself, as its time element is the date of the review
+	date := ""
+	for parent := replyText.Parent(); parent.Length() > 0 && !parent.IsSelection(s); parent = parent.Parent() {
+		if replyTime := parent.Find("time").First(); replyTime.Length() > 0 {
+			date = replyTime.AttrOr("datetime", "")
+
+			break
+		}
+	}
+
+	return &Reply{
+		Text:       replyText.Text(),
+		Date:       date,
+		ParsedDate: parseDate(date),
+	}
+}
+
+// replyLatency returns how long the business took to reply to the review posted at date,
+// zero when there is no reply or either date is unknown.
+func replyLatency(date time.Time, reply *Reply) time.Duration {
+	if reply == nil || date.IsZero() || reply.ParsedDate.IsZero() {
+		return 0
+	}
+
+	return reply.ParsedDate.Sub(date)
+}
+
+// parseImages extracts the URLs of the images uploaded by the reviewer. The images may be lazy loaded,
+// then the URL is in data-src until they're shown.
+func parseImages(s *goquery.Selection, selector, productURL string) []string {
+	var images []string
+	s.Find(selector).Each(func(i int, image *goquery.Selection) {
+		src := image.AttrOr("src", "")
+		if src == "" || strings.HasPrefix(src, "data:") {
+			src = image.AttrOr("data-src", "")
+		}
+
+		if src = reviewLink(productURL, src); src != "" {
+			images = append(images, src)
+		}
+	})
+
+	return images
+}
+
+// parseExperienceDate extracts the date from the "Date of experience:" line of the card.
+// It returns an empty string when the line is absent, which happens on some older reviews.
+func parseExperienceDate(s *goquery.Selection) string {
+	line := s.Find("[data-service-review-date-of-experience-typography]").First().Text()
+	if _, date, found := strings.Cut(line, ":"); found {
+		return strings.TrimSpace(date)
+	}
+
+	return strings.TrimSpace(line)
+}
+
+// parseExperienceDateTime parses the date of experience into UTC time.
+// It returns the zero time when the date is missing or has an unknown format.
+func parseExperienceDateTime(date string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(experienceDateLayout, date)
+	if err != nil {
+		slog.Debug("Cannot parse date of experience", "date", date, "error", err)
+
+		return time.Time{}
+	}
+
+	return parsed.UTC()
+}
+
+// isVerified detects the "Verified" label which Trustpilot shows on the verified reviews.
+func isVerified(s *goquery.Selection) bool {
+	verified := false
+	s.Find("[data-review-label-tooltip-trigger-typography]").EachWithBreak(func(i int, label *goquery.Selection) bool {
+		verified = strings.Contains(strings.ToLower(label.Text()), "verified")
+
+		return !verified
+	})
+
+	return verified
+}
+
+// isInvited checks the review labels for the "Invited" one, which marks the reviews solicited by the company.
+func isInvited(s *goquery.Selection) bool {
+	invited := false
+	s.Find("[data-review-label-tooltip-trigger-typography]").EachWithBreak(func(i int, label *goquery.Selection) bool {
+		invited = strings.Contains(strings.ToLower(label.Text()), "invited")
+
+		return !invited
+	})
+
+	return invited
+}
+
+// parseUseful extracts the number of people who found the review useful. The count is shown either on the like
+// button or as the "N people found this review useful" text, and it's 0 when the review has no likes yet.
+func parseUseful(s *goquery.Selection) int {
+	if button := s.Find("[data-review-like-button]").First(); button.Length() > 0 {
+		if count := parseFirstNumber(button.Text()); count > 0 {
+			return count
+		}
+	}
+
+	useful := 0
+	s.Find("span, p, div").EachWithBreak(func(i int, element *goquery.Selection) bool {
+		text := strings.ToLower(element.Text())
+		if !strings.Contains(text, "found this review useful") {
+			return true
+		}
+
+		useful = parseFirstNumber(text)
+		// the outer elements contain the text as well, so we keep looking for the innermost one
+		return element.Children().Length() > 0
+	})
+
+	return useful
+}
+
+// parseRating extracts the rating alt text and the number of stars of the review card. The stars come from
+// the rating data attribute or the star image file name, which don't depend on the page language,
+// and fall back to the localized alt text.
+func parseRating(s *goquery.Selection, selector string) (string, int) {
+	ratingElement := s.Find(selector).First()
+
+	starsImage := ratingElement.Find("img").First()
+	if starsImage.Length() == 0 {
+		starsImage = s.Find("img").First()
+	}
+	rating := starsImage.AttrOr("alt", "")
+
+	if stars, err := strconv.Atoi(strings.TrimSpace(ratingElement.AttrOr("data-service-review-rating", ""))); err == nil && validStars(stars) {
+		return rating, stars
+	}
+
+	// the star images are named by the rating, like "stars-4.svg"
+	if match := starsImageRe.FindStringSubmatch(starsImage.AttrOr("src", "")); match != nil {
+		if stars, err := strconv.Atoi(match[1]); err == nil && validStars(stars) {
+			return rating, stars
+		}
+	}
+
+	return rating, parseStars(rating)
+}
+
+func validStars(stars int) bool {
+	return stars >= 1 && stars <= 5
+}
+
+// parseStars extracts the number of stars from the rating alt text, like "Rated 5 out of 5 stars".
+// It returns 0 when there is no number in the text or the number isn't a valid rating.
+func parseStars(rating string) int {
+	if stars := parseFirstNumber(rating); validStars(stars) {
+		return stars
+	}
+
+	return 0
+}
+
+// parseFirstNumber extracts the first integer from the text, like 3 from "3 reviews".
+// It returns 0 when there is no number in the text.
+func parseFirstNumber(text string) int {
+	number, err := strconv.Atoi(numberRe.FindString(text))
+	if err != nil {
+		return 0
+	}
+
+	return number
+}
+
+// parseCardDate returns the machine-readable date of the review card. When the datetime attribute is absent,
+// the human-readable date is normalized to the same format, and it's empty only when both are missing or unknown.
+func parseCardDate(s *goquery.Selection, selector string) string {
+	dateElement := s.Find(selector).First()
+	if date := dateElement.AttrOr("datetime", ""); date != "" {
+		return date
+	}
+
+	text := dateElement.Text()
+	if text == "" {
+		text = s.Find("[data-service-review-date-time-ago]").First().Text()
+	}
+
+	date, ok := parseDateText(text, time.Now())
+	if !ok {
+		if text != "" {
+			slog.Debug("Cannot parse review date text", "text", text)
+		}
+
+		return ""
+	}
+
+	return date.Format(time.RFC3339)
+}
+
+// parseDate parses the ISO 8601 datetime attribute of the review into UTC time.
+// It returns the zero time when the date is missing or malformed.
+func parseDate(date string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		slog.Debug("Cannot parse review date", "date", date, "error", err)
+
+		return time.Time{}
+	}
+
+	return parsed.UTC()
+}
@@ -0,0 +1,387 @@
+package trustpilot
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// update rewrites the golden files with the current output: go test ./pkg/trustpilot -run Golden -update
+var update = flag.Bool("update", false, "update the golden files")
+
+const testProductURL = "https://www.trustpilot.com/review/example.com"
+
+// pageGolden is what the extraction produces from a saved review page.
+type pageGolden struct {
+	LastPage int       `json:"last_page"`
+	HasNext  bool      `json:"has_next"`
+	Business Business  `json:"business"`
+	Reviews  []*Review `json:"reviews"`
+}
+
+func TestParsePageGolden(t *testing.T) {
+	fixtures := []string{
+		"multi_page",
+		"single_page",
+		"reply",
+		"next_data",
+		"next_data_images",
+		"cards",
+	}
+
+	scraper := NewScraper()
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			doc := loadFixture(t, fixture+".html")
+
+			lastPage, _ := detectLastPage(doc)
+			_, hasNext := nextPageURL(doc, testProductURL)
+			got := &pageGolden{
+				LastPage: lastPage,
+				HasNext:  hasNext,
+				Business: parseBusiness(doc),
+				Reviews:  scraper.parsePage(doc, testProductURL),
+			}
+
+			if len(got.Reviews) == 0 {
+				t.Fatal("no reviews extracted")
+			}
+
+			gotJSON, err := json.MarshalIndent(got, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotJSON = append(gotJSON, '\n')
+
+			goldenPath := filepath.Join("testdata", fixture+".golden.json")
+			if *update {
+				if err := os.WriteFile(goldenPath, gotJSON, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("read golden file, run with -update to create it: %v", err)
+			}
+
+			if !bytes.Equal(gotJSON, want) {
+				t.Errorf("extracted reviews differ from %s, run with -update if the change is expected\ngot:\n%s", goldenPath, gotJSON)
+			}
+		})
+	}
+}
+
+func loadFixture(tb testing.TB, name string) *goquery.Document {
+	tb.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	doc, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return doc
+}
+
+// BenchmarkExtractReviews measures the card markup parsing of a large page, visiting the review cards only
+// and scanning every div as the fallback does when the card selector finds nothing.
+func BenchmarkExtractReviews(b *testing.B) {
+	page, err := os.ReadFile(filepath.Join("testdata", "large_page.html"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	noCards := DefaultSelectors
+	noCards.Card = "div[data-no-such-card]"
+
+	benchmarks := []struct {
+		name      string
+		selectors *Selectors
+	}{
+		{name: "cards", selectors: &DefaultSelectors},
+		{name: "all_divs", selectors: &noCards},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(page)))
+
+			for i := 0; i < b.N; i++ {
+				doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if reviews := parsePageReviews(doc, testProductURL, bm.selectors, false); len(reviews) != 20 {
+					b.Fatalf("extracted %d reviews, want 20", len(reviews))
+				}
+			}
+		})
+	}
+}
+
+// FuzzParseReviewCard feeds arbitrary markup to the card parser, which must neither panic nor return
+// the values out of their bounds. The corpus is seeded with the cards of the saved pages, and the inputs
+// found before are kept in testdata/fuzz.
+func FuzzParseReviewCard(f *testing.F) {
+	for _, fixture := range []string{"multi_page.html", "single_page.html", "reply.html"} {
+		loadFixture(f, fixture).Find(DefaultSelectors.Card).Each(func(_ int, card *goquery.Selection) {
+			html, err := goquery.OuterHtml(card)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(html)
+		})
+	}
+
+	f.Fuzz(func(t *testing.T, html string) {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		if err != nil {
+			return
+		}
+
+		doc.Find("div").Each(func(_ int, card *goquery.Selection) {
+			review, ok := parseReviewCard(card, testProductURL, &DefaultSelectors)
+			if !ok {
+				return
+			}
+
+			if review.Stars < 0 || review.Stars > 5 {
+				t.Errorf("stars %d out of 0..5", review.Stars)
+			}
+			if review.Useful < 0 {
+				t.Errorf("negative useful count %d", review.Useful)
+			}
+			if review.AuthorReviewCount < 0 {
+				t.Errorf("negative author review count %d", review.AuthorReviewCount)
+			}
+		})
+	})
+}
+
+func TestParseStars(t *testing.T) {
+	tests := []struct {
+		rating string
+		want   int
+	}{
+		{rating: "Rated 1 out of 5 stars", want: 1},
+		{rating: "Rated 2 out of 5 stars", want: 2},
+		{rating: "Rated 3 out of 5 stars", want: 3},
+		{rating: "Rated 4 out of 5 stars", want: 4},
+		{rating: "Rated 5 out of 5 stars", want: 5},
+		{rating: "Rated 0 out of 5 stars", want: 0},
+		{rating: "Rated 9 out of 5 stars", want: 0},
+		{rating: "Rated 99999999999999999999 out of 5 stars", want: 0},
+		{rating: "No rating", want: 0},
+		{rating: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseStars(tt.rating); got != tt.want {
+			t.Errorf("parseStars(%q) = %d, want %d", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		name      string
+		card      string
+		wantText  string
+		wantStars int
+	}{
+		{
+			name:      "data attribute",
+			card:      `<div data-service-review-rating="4"><img src="/stars-2.svg" alt="Rated 1 out of 5 stars"></div>`,
+			wantText:  "Rated 1 out of 5 stars",
+			wantStars: 4,
+		},
+		{
+			name:      "invalid data attribute falls back to the image name",
+			card:      `<div data-service-review-rating="7"><img src="https://cdn.trustpilot.net/stars/stars-2.svg" alt="Rated 1 out of 5 stars"></div>`,
+			wantText:  "Rated 1 out of 5 stars",
+			wantStars: 2,
+		},
+		{
+			name:      "image name",
+			card:      `<div><img src="https://cdn.trustpilot.net/stars/stars-3.svg?v=2" alt="Rated 1 out of 5 stars"></div>`,
+			wantText:  "Rated 1 out of 5 stars",
+			wantStars: 3,
+		},
+		{
+			name:      "alt text",
+			card:      `<div><img src="/rating.svg" alt="Rated 5 out of 5 stars"></div>`,
+			wantText:  "Rated 5 out of 5 stars",
+			wantStars: 5,
+		},
+		{
+			name:      "out of range alt text",
+			card:      `<div><img src="/rating.svg" alt="Rated 6 out of 5 stars"></div>`,
+			wantText:  "Rated 6 out of 5 stars",
+			wantStars: 0,
+		},
+		{
+			name:      "german data attribute",
+			card:      `<div data-service-review-rating="2"><img src="/rating.svg" alt="Bewertet mit 2 von 5 Sternen"></div>`,
+			wantText:  "Bewertet mit 2 von 5 Sternen",
+			wantStars: 2,
+		},
+		{
+			name:      "german alt text",
+			card:      `<div><img src="/rating.svg" alt="Bewertet mit 4 von 5 Sternen"></div>`,
+			wantText:  "Bewertet mit 4 von 5 Sternen",
+			wantStars: 4,
+		},
+		{
+			name:      "french data attribute",
+			card:      `<div data-service-review-rating="5"><img src="/rating.svg" alt="Noté 5 sur 5 étoiles"></div>`,
+			wantText:  "Noté 5 sur 5 étoiles",
+			wantStars: 5,
+		},
+		{
+			name:      "french alt text",
+			card:      `<div><img src="/rating.svg" alt="Noté 3 sur 5 étoiles"></div>`,
+			wantText:  "Noté 3 sur 5 étoiles",
+			wantStars: 3,
+		},
+		{
+			name:      "french image name",
+			card:      `<div><img src="https://cdn.trustpilot.net/stars/stars-1.svg" alt="Noté 1 sur 5 étoiles"></div>`,
+			wantText:  "Noté 1 sur 5 étoiles",
+			wantStars: 1,
+		},
+		{
+			name:      "no rating",
+			card:      `<div><p>No stars here</p></div>`,
+			wantText:  "",
+			wantStars: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, stars := parseRating(parseCard(t, "<div>"+tt.card+"</div>"), DefaultSelectors.Rating)
+			if text != tt.wantText || stars != tt.wantStars {
+				t.Errorf("got %q, %d stars, want %q, %d stars", text, stars, tt.wantText, tt.wantStars)
+			}
+		})
+	}
+}
+
+func TestReviewLink(t *testing.T) {
+	const productURL = "https://www.trustpilot.com/review/example.com?page=2"
+
+	tests := []struct {
+		name string
+		href string
+		want string
+	}{
+		{name: "absolute", href: "https://www.trustpilot.com/reviews/abc123", want: "https://www.trustpilot.com/reviews/abc123"},
+		{name: "root-relative", href: "/reviews/abc123", want: "https://www.trustpilot.com/reviews/abc123"},
+		{name: "relative", href: "../reviews/abc123", want: "https://www.trustpilot.com/reviews/abc123"},
+		{name: "whitespace-padded", href: " \n\t/reviews/abc123 \n", want: "https://www.trustpilot.com/reviews/abc123"},
+		{name: "whitespace only", href: " \n ", want: ""},
+		{name: "empty", href: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reviewLink(productURL, tt.href); got != tt.want {
+				t.Errorf("reviewLink(%q) = %q, want %q", tt.href, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePageNormalizesWhitespace(t *testing.T) {
+	doc := loadFixture(t, "cards.html")
+
+	tests := []struct {
+		name       string
+		raw        bool
+		wantText   string
+		wantTitle  string
+		wantAuthor string
+	}{
+		{
+			name:       "normalized",
+			wantText:   "The app works. The plan is expensive for a small team.",
+			wantTitle:  "Okay overall, but pricey",
+			wantAuthor: "Jean Dupont",
+		},
+		{
+			name:       "raw",
+			raw:        true,
+			wantText:   "\n          The app works.\u00a0\u00a0The plan\n\tis expensive\n          for a small team.\u202f\n        ",
+			wantTitle:  "\n          Okay\u00a0overall,\n          but   pricey\u00a0\n        ",
+			wantAuthor: "\n        \u00a0Jean\u00a0\u00a0Dupont\u00a0\n      ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviews := NewScraper(WithRawText(tt.raw)).parsePage(doc, testProductURL)
+
+			var review *Review
+			for _, r := range reviews {
+				if r.ID == "65f1a2b3c4d5e6f7a8b9c0f4" {
+					review = r
+				}
+			}
+			if review == nil {
+				t.Fatal("messy whitespace card not extracted")
+			}
+
+			if review.Text != tt.wantText {
+				t.Errorf("got text %q, want %q", review.Text, tt.wantText)
+			}
+			if review.Title != tt.wantTitle {
+				t.Errorf("got title %q, want %q", review.Title, tt.wantTitle)
+			}
+			if review.Author != tt.wantAuthor {
+				t.Errorf("got author %q, want %q", review.Author, tt.wantAuthor)
+			}
+		})
+	}
+}
+
+func TestEmptyStatePage(t *testing.T) {
+	doc := loadFixture(t, "empty_state.html")
+
+	if err := checkMarkup(doc, testProductURL); err != nil {
+		t.Fatalf("empty state page is not recognized as a review page: %v", err)
+	}
+
+	if !hasEmptyState(doc) {
+		t.Error("no reviews yet placeholder is not detected")
+	}
+
+	if reviews := NewScraper().parsePage(doc, testProductURL); len(reviews) != 0 {
+		t.Errorf("got %d reviews, want none", len(reviews))
+	}
+}
+
+// parseCard parses the markup of a review card and returns its outermost element.
+func parseCard(tb testing.TB, html string) *goquery.Selection {
+	tb.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body>` + html + `</body></html>`))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return doc.Find("body").Children().First()
+}
@@ -0,0 +1,73 @@
+package trustpilot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ReviewByURL scrapes the single review from its permalink, like "https://www.trustpilot.com/reviews/645a1b2c3d4e5f6a7b8c9d0e".
+// It's useful to refresh a known review, e.g. to get the latest reply of the business.
+func (s *Scraper) ReviewByURL(ctx context.Context, reviewURL string) (*Review, error) {
+	permalink, id, err := parseReviewURL(reviewURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.ignoreRobots {
+		if err := s.checkRobots(ctx, permalink.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := s.fetchDocument(ctx, permalink.String())
+	if err != nil {
+		return nil, err
+	}
+
+	pageURL := permalink.String()
+	if doc.Url != nil {
+		pageURL = doc.Url.String()
+	}
+
+	// the permalink page may show other reviews too, so we look for the requested one by its ID
+	reviews := s.normalizeReviews(parsePageReviews(doc, pageURL, &s.selectors, s.includeHTML))
+	for _, review := range reviews {
+		if review.ID == id {
+			return review, nil
+		}
+	}
+
+	if len(reviews) == 1 && reviews[0].ID == "" {
+		return reviews[0], nil
+	}
+
+	return nil, fmt.Errorf("%w for review %s, the page layout may have changed", ErrNoReviews, id)
+}
+
+// parseReviewURL checks that the URL is a Trustpilot review permalink and extracts the review ID from it.
+func parseReviewURL(reviewURL string) (*url.URL, string, error) {
+	permalink, err := url.Parse(strings.TrimSpace(reviewURL))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid review URL %q: %w", reviewURL, err)
+	}
+
+	if permalink.Scheme != "https" && permalink.Scheme != "http" {
+		return nil, "", fmt.Errorf("invalid review URL %q, expected an http or https URL", reviewURL)
+	}
+
+	if err := ValidateDomain(strings.ToLower(permalink.Hostname())); err != nil {
+		return nil, "", fmt.Errorf("invalid review URL %q: %w", reviewURL, err)
+	}
+
+	id, found := strings.CutPrefix(strings.TrimSuffix(permalink.Path, "/"), "/reviews/")
+	if !found || id == "" || strings.Contains(id, "/") {
+		return nil, "", fmt.Errorf("invalid review URL %q, expected a link like https://%s/reviews/<id>", reviewURL, DefaultDomain)
+	}
+
+	permalink.Scheme = "https"
+	permalink.RawQuery, permalink.Fragment = "", ""
+
+	return permalink, id, nil
+}
@@ -0,0 +1,60 @@
+package trustpilot
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidProduct is returned when the product is neither a product name, a website nor a Trustpilot review page.
+var ErrInvalidProduct = errors.New("invalid product")
+
+var productSlugRe = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}._-]*$`)
+
+// NormalizeProduct extracts the Trustpilot product slug, like "invideo.io", from a bare slug,
+// a review page URL, like "https://www.trustpilot.com/review/invideo.io", or the website of the business.
+func NormalizeProduct(input string) (string, error) {
+	product := strings.TrimSpace(input)
+	if product == "" {
+		return "", fmt.Errorf("%w %q: empty", ErrInvalidProduct, input)
+	}
+
+	if strings.Contains(product, "/") {
+		rawURL := product
+		if !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "/") {
+			rawURL = "https://" + rawURL
+		}
+
+		productURL, err := url.Parse(rawURL)
+		if err != nil {
+			return "", fmt.Errorf("%w %q: %w", ErrInvalidProduct, input, err)
+		}
+
+		path := strings.Trim(productURL.Path, "/")
+		host := strings.ToLower(productURL.Hostname())
+		switch {
+		case host == "" || domainRe.MatchString(host):
+			// the review page, like trustpilot.com/review/invideo.io
+			slug, found := strings.CutPrefix(path, "review/")
+			if !found {
+				return "", fmt.Errorf("%w %q: expected a review page like https://%s/review/<product>", ErrInvalidProduct, input, DefaultDomain)
+			}
+
+			product, _, _ = strings.Cut(slug, "/")
+		case path == "":
+			// the website of the business, which is the slug on Trustpilot
+			product = host
+		default:
+			return "", fmt.Errorf("%w %q: expected a product name, a website or a Trustpilot review page", ErrInvalidProduct, input)
+		}
+	}
+
+	product = strings.ToLower(product)
+	if !productSlugRe.MatchString(product) {
+		return "", fmt.Errorf("%w %q: unexpected characters in %q", ErrInvalidProduct, input, product)
+	}
+
+	return product, nil
+}
@@ -0,0 +1,98 @@
+package trustpilot
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Progress receives the progress of the scraping, e.g. to drive a UI or metrics.
+// Pages are scraped in parallel, so the methods may be called concurrently and must be safe for that.
+type Progress interface {
+	// PageStarted is called before the page is requested.
+	PageStarted(page int)
+	// PageDone is called once the page is scraped with the number of reviews found on it.
+	PageDone(page, reviews int)
+	// Finished is called once the product is scraped with the total number of collected reviews.
+	Finished(total int)
+}
+
+// PagesReporter is an optional extension of Progress, which learns the number of pages before they're scraped,
+// e.g. to show "0/120 pages" right away.
+type PagesReporter interface {
+	// PagesFound is called once the number of pages to request is known, before the pages after the first one
+	// are requested. It's not called when the pages are discovered one by one by the sequential pagination.
+	PagesFound(total int)
+}
+
+type noopProgress struct{}
+
+func (noopProgress) PageStarted(int)   {}
+func (noopProgress) PageDone(int, int) {}
+func (noopProgress) Finished(int)      {}
+
+// terminalProgress prints a single updating line with the progress.
+type terminalProgress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	started int
+	done    int
+	reviews int
+	// total is the number of pages to scrape, 0 until it's known
+	total int
+}
+
+// NewTerminalProgress creates a Progress which keeps a one-line progress indicator updated in w, usually os.Stderr.
+// It follows one product at a time, so it can't be combined with WithProductConcurrency above 1.
+func NewTerminalProgress(w io.Writer) Progress {
+	return &terminalProgress{w: w}
+}
+
+func (p *terminalProgress) PageStarted(int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.started++
+	p.print()
+}
+
+func (p *terminalProgress) PageDone(_, reviews int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.reviews += reviews
+	p.print()
+}
+
+func (p *terminalProgress) PagesFound(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+	p.print()
+}
+
+func (p *terminalProgress) Finished(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(p.w, "\rpages: %d/%d, reviews: %d, done: %d collected\n", p.done, p.pages(), p.reviews, total)
+
+	// the next product starts from scratch
+	p.started, p.done, p.reviews, p.total = 0, 0, 0, 0
+}
+
+func (p *terminalProgress) print() {
+	// the carriage return rewrites the same line, the trailing spaces clean the leftovers of a longer line
+	fmt.Fprintf(p.w, "\rpages: %d/%d, reviews: %d   ", p.done, p.pages(), p.reviews)
+}
+
+// pages is the number of pages to scrape when it's known, otherwise the number of pages started so far.
+func (p *terminalProgress) pages() int {
+	if p.total > 0 {
+		return p.total
+	}
+
+	return p.started
+}
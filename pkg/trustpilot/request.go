@@ -0,0 +1,235 @@
+package trustpilot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// fetch makes a GET request to the url, retrying network errors and 5xx responses with exponential backoff.
+// 429 responses are retried after the delay requested by the Retry-After header.
+// Any other non-2xx response is returned as an error.
+// The caller must close the body of the returned response.
+func (s *Scraper) fetch(ctx context.Context, url string) (*http.Response, error) {
+	var (
+		lastErr error
+		delay   time.Duration
+	)
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			s.logger.Warn("Retrying request", "url", url, "delay", delay, "attempt", attempt+1, "max_attempts", s.maxRetries+1, "error", lastErr)
+
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		// every attempt counts towards the rate limit, as it's a request to Trustpilot anyway
+		if err := s.waitLimiter(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.userAgent != "" {
+			req.Header.Set("User-Agent", s.userAgent)
+		}
+
+		// the headers may carry a session, so they never leave the Trustpilot domain, e.g. for the image downloads
+		if req.URL.Host == s.domain {
+			for key, values := range s.headers {
+				req.Header[key] = values
+			}
+		}
+
+		start := time.Now()
+		res, err := s.do(ctx, req)
+		s.metrics.requestDone(time.Since(start))
+		if err != nil {
+			// there is no point to retry if the request was cancelled by the caller
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			s.metrics.requestFailed("network")
+			lastErr = err
+			delay = s.backoff(attempt + 1)
+
+			continue
+		}
+
+		// a challenge won't go away on retry, so we fail right away
+		if err := s.checkChallengeResponse(res, url); err != nil {
+			s.metrics.requestFailed("challenge")
+			s.cache.evict(res.Request)
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+
+			return nil, err
+		}
+
+		if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+			s.metrics.requestFailed(strconv.Itoa(res.StatusCode))
+		}
+
+		switch {
+		case res.StatusCode == http.StatusTooManyRequests:
+			lastErr = fmt.Errorf("rate limited with status %s", res.Status)
+			delay = s.retryAfter(res.Header.Get("Retry-After"), attempt+1)
+			s.logger.Warn("Rate limited by Trustpilot, waiting before the next attempt", "url", url, "delay", delay)
+		case res.StatusCode >= http.StatusInternalServerError:
+			lastErr = fmt.Errorf("server responded with status %s", res.Status)
+			delay = s.backoff(attempt + 1)
+		case res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices:
+			// other statuses, like 404, won't change on retry
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+
+			return nil, &StatusError{URL: url, StatusCode: res.StatusCode, Status: res.Status}
+		default:
+			return res, nil
+		}
+
+		// drain the body so the connection can be reused for the next attempt
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+
+	return nil, fmt.Errorf("request to %s failed after %d attempts: %w", url, s.maxRetries+1, lastErr)
+}
+
+// fetchBody requests the url and passes the response to read. The body is always drained and closed afterwards,
+// so the connection can be reused even when read stops early or fails.
+func (s *Scraper) fetchBody(ctx context.Context, url string, read func(res *http.Response) error) error {
+	res, err := s.fetch(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}()
+
+	return read(res)
+}
+
+// do makes a single attempt of the request, limited by the page timeout. The timeout covers reading the body
+// as well, so it's released only when the body is closed.
+func (s *Scraper) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if s.pageTimeout <= 0 {
+		return s.client.Do(req)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, s.pageTimeout)
+	res, err := s.client.Do(req.WithContext(attemptCtx))
+	if err != nil {
+		timedOut := errors.Is(attemptCtx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if timedOut && ctx.Err() == nil {
+			return nil, fmt.Errorf("request timed out after %s: %w", s.pageTimeout, err)
+		}
+
+		return nil, err
+	}
+
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+
+	return res, nil
+}
+
+// cancelOnClose releases the context of the request once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+
+	return c.ReadCloser.Close()
+}
+
+// retryAfter returns the delay requested by the Retry-After header value, which is either a number of seconds
+// or an HTTP date. It falls back to the exponential backoff when the header is missing or malformed,
+// and never exceeds the configured ceiling.
+func (s *Scraper) retryAfter(header string, attempt int) time.Duration {
+	delay := s.backoff(attempt)
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+	}
+
+	if delay > s.maxRetryAfter {
+		return s.maxRetryAfter
+	}
+
+	return delay
+}
+
+// backoff returns the delay before the retry attempt: the base delay doubled for every attempt plus a random jitter.
+func (s *Scraper) backoff(attempt int) time.Duration {
+	delay := s.retryBaseDelay << (attempt - 1)
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// pageDelay returns a random delay from the configured range to wait before the page request.
+func (s *Scraper) pageDelay() time.Duration {
+	if s.maxDelay <= s.minDelay {
+		return s.minDelay
+	}
+
+	return s.minDelay + time.Duration(rand.Int63n(int64(s.maxDelay-s.minDelay)+1))
+}
+
+// sleep waits for the duration or until the context is done.
+// waitLimiter waits for the rate limiter. The limiter fails right away when the wait would pass the deadline
+// of the context, so we report it as the deadline, which it effectively is, rather than a limiter error.
+func (s *Scraper) waitLimiter(ctx context.Context) error {
+	err := s.limiter.Wait(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	// the limiter fails regardless of the deadline only when it can never allow a request
+	if _, ok := ctx.Deadline(); ok && (s.limiter.Limit() == rate.Inf || s.limiter.Burst() > 0) {
+		return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+	}
+
+	return err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
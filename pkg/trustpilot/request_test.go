@@ -0,0 +1,35 @@
+package trustpilot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestFetchRateLimitPastDeadline(t *testing.T) {
+	site := newTestSite(t, 1)
+
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	// the next request is allowed only in an hour, long after the deadline
+	limiter.Allow()
+	scraper := site.scraper(WithRateLimiter(limiter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := scraper.fetch(ctx, site.server.URL+"/review/"+testProduct)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if !IsPartial(err) {
+		t.Errorf("error %v is not reported as partial", err)
+	}
+
+	if hits := site.hits.Load(); hits != 0 {
+		t.Errorf("requested %d pages past the deadline", hits)
+	}
+}
@@ -0,0 +1,66 @@
+package trustpilot
+
+import "time"
+
+type Review struct {
+	// ID is the Trustpilot review ID, the key to deduplicate reviews across runs.
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	// Date is the original datetime attribute of the review.
+	Date string `json:"date"`
+	// ParsedDate is Date in UTC, zero when it cannot be parsed.
+	ParsedDate time.Time `json:"parsed_date"`
+	// RatingText is the original rating text, like "Rated 5 out of 5 stars". It keeps the "rating" key for compatibility.
+	RatingText string `json:"rating"`
+	// Stars is the rating parsed from RatingText, 0 when it cannot be parsed.
+	Stars int    `json:"stars"`
+	Title string `json:"title"`
+	Link  string `json:"link"`
+	// Author is the display name of the reviewer.
+	Author string `json:"author"`
+	// Country is the uppercase country code of the reviewer, like "US".
+	Country string `json:"country"`
+	// AuthorReviewCount is the total number of reviews written by the reviewer, 0 when unknown.
+	AuthorReviewCount int `json:"author_review_count"`
+	// Verified is true when the review carries the Trustpilot verification label.
+	Verified bool `json:"verified"`
+	// Reply is the response of the business to the review, nil when there is no reply.
+	Reply *Reply `json:"reply,omitempty"`
+	// ReplyLatency is how long the business took to reply, from ParsedDate to Reply.ParsedDate.
+	// It's zero when there is no reply or either date is unknown, and it's encoded in nanoseconds.
+	ReplyLatency time.Duration `json:"reply_latency,omitempty"`
+	// Images are the absolute URLs of the images uploaded by the reviewer, empty when there are none.
+	Images []string `json:"images,omitempty"`
+	// ExperienceDate is the original "Date of experience" of the review, empty when it's absent.
+	ExperienceDate string `json:"experience_date"`
+	// ParsedExperienceDate is ExperienceDate in UTC, zero when it cannot be parsed.
+	ParsedExperienceDate time.Time `json:"parsed_experience_date"`
+	// Invited is true when the company invited the reviewer to write the review, false for organic reviews.
+	Invited bool `json:"invited"`
+	// Useful is the number of people who found the review useful, 0 when nobody did or it's unknown.
+	Useful int `json:"useful"`
+	// Language is the ISO 639-1 code of the detected language of the review, empty unless the detection is enabled.
+	Language string `json:"language,omitempty"`
+	// RawHTML is the inner markup of the review card, empty unless it's requested with WithRawHTML.
+	// It's empty for the reviews taken from the __NEXT_DATA__ script, as their fields don't depend on the markup.
+	RawHTML string `json:"raw_html,omitempty"`
+}
+
+type Reply struct {
+	Text string `json:"text"`
+	Date string `json:"date"`
+	// ParsedDate is Date in UTC, zero when it cannot be parsed.
+	ParsedDate time.Time `json:"parsed_date"`
+}
+
+type ProductReviews struct {
+	ProductName string    `json:"product_name"`
+	Reviews     []*Review `json:"reviews"`
+	Stats       *Stats    `json:"stats"`
+	Business    Business  `json:"business"`
+	// Filtered is the number of the scraped reviews dropped by the filters, so Reviews and Filtered together
+	// are all the reviews shown on the scraped pages, except the duplicates.
+	Filtered int `json:"filtered"`
+	// FailedPages are the numbers of the pages which couldn't be scraped, so the reviews are incomplete when it's set.
+	FailedPages []int `json:"failed_pages,omitempty"`
+}
@@ -0,0 +1,174 @@
+package trustpilot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ErrDisallowedByRobots is returned when robots.txt of the domain disallows scraping the reviews.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// robotsRule is a single Allow or Disallow line of robots.txt.
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// robotsRules holds the rules of robots.txt which apply to our User-Agent.
+type robotsRules struct {
+	rules []robotsRule
+}
+
+// allowed reports whether the path may be scraped. The longest matching rule wins, and Allow wins the ties.
+func (r *robotsRules) allowed(path string) bool {
+	allowed, matchLength := true, -1
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+
+		if len(rule.pattern) > matchLength || (len(rule.pattern) == matchLength && rule.allow) {
+			allowed, matchLength = rule.allow, len(rule.pattern)
+		}
+	}
+
+	return allowed
+}
+
+// checkRobots verifies that robots.txt of the domain allows scraping the path. The rules are fetched once
+// and cached on the scraper.
+func (s *Scraper) checkRobots(ctx context.Context, path string) error {
+	rules, err := s.robotsRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !rules.allowed(path) {
+		return fmt.Errorf("%s%s: %w", s.domain, path, ErrDisallowedByRobots)
+	}
+
+	return nil
+}
+
+func (s *Scraper) robotsRules(ctx context.Context) (*robotsRules, error) {
+	s.robotsMu.Lock()
+	defer s.robotsMu.Unlock()
+
+	if s.robots != nil {
+		return s.robots, nil
+	}
+
+	robotsURL := fmt.Sprintf("https://%s/robots.txt", s.domain)
+	var rules *robotsRules
+	err := s.fetchBody(ctx, robotsURL, func(res *http.Response) error {
+		var err error
+		rules, err = parseRobots(res.Body, s.userAgent)
+
+		return err
+	})
+	if err != nil {
+		// a missing robots.txt allows everything
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError {
+			s.robots = &robotsRules{}
+
+			return s.robots, nil
+		}
+
+		return nil, fmt.Errorf("cannot fetch robots.txt: %w", err)
+	}
+
+	s.robots = rules
+
+	return s.robots, nil
+}
+
+// parseRobots parses robots.txt and keeps the rules of the group matching the User-Agent,
+// falling back to the "*" group when no group names it.
+func parseRobots(r io.Reader, userAgent string) (*robotsRules, error) {
+	userAgent = strings.ToLower(userAgent)
+
+	var (
+		specific, wildcard []robotsRule
+		hasSpecific        bool
+		// agents of the current group, which may be listed on several consecutive lines
+		groupAgents   []string
+		inGroupAgents bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inGroupAgents {
+				groupAgents = nil
+			}
+
+			groupAgents = append(groupAgents, strings.ToLower(value))
+			inGroupAgents = true
+		case "allow", "disallow":
+			inGroupAgents = false
+			// an empty Disallow allows everything
+			if value == "" {
+				continue
+			}
+
+			rule := robotsRule{allow: key == "allow", pattern: value, re: robotsPatternRe(value)}
+			for _, agent := range groupAgents {
+				switch {
+				case agent == "*":
+					wildcard = append(wildcard, rule)
+				case userAgent != "" && strings.Contains(userAgent, agent):
+					specific = append(specific, rule)
+					hasSpecific = true
+				}
+			}
+		default:
+			inGroupAgents = false
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if hasSpecific {
+		return &robotsRules{rules: specific}, nil
+	}
+
+	return &robotsRules{rules: wildcard}, nil
+}
+
+// robotsPatternRe converts the robots.txt path pattern into a regexp, supporting the "*" and "$" wildcards.
+func robotsPatternRe(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
@@ -0,0 +1,962 @@
+package trustpilot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/time/rate"
+)
+
+const (
+	scrapingURL     = "https://%s/review/%s"
+	scrapingPageURL = "%s?page=%d"
+
+	// DefaultDomain is the Trustpilot host used unless another one is configured
+	DefaultDomain = "www.trustpilot.com"
+)
+
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxRetries     = 2
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultMaxRetryAfter  = time.Minute
+	defaultConcurrency    = 5
+	defaultRPS            = 2
+	defaultPageTimeout    = 15 * time.Second
+	defaultUserAgent      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) " +
+		"Chrome/124.0.0.0 Safari/537.36"
+)
+
+var pageParamRe = regexp.MustCompile(`page=(\d+)`)
+
+var domainRe = regexp.MustCompile(`^([a-z0-9-]+\.)?trustpilot\.[a-z]{2,}(\.[a-z]{2,})?$`)
+
+// Scraper collects reviews of a product from Trustpilot.
+type Scraper struct {
+	client         *http.Client
+	maxRetries     int
+	retryBaseDelay time.Duration
+	maxRetryAfter  time.Duration
+	userAgent      string
+	filters        []func(review *Review) bool
+	domain         string
+	sortOrder      SortOrder
+	startPage      int
+	endPage        int
+	maxReviews     int
+	newerThan      time.Time
+	concurrency    int
+	limiter        *rate.Limiter
+	proxy          *url.URL
+	ignoreRobots   bool
+	pagination     Pagination
+	maxPages       int
+	pageTimeout    time.Duration
+	logger         *slog.Logger
+	progress       Progress
+
+	challengeMarkers   ChallengeMarkers
+	selectors          Selectors
+	cacheDir           string
+	cacheTTL           time.Duration
+	cache              *cacheTransport
+	detectLanguage     bool
+	transform          func(review *Review) *Review
+	metrics            *Metrics
+	pageOrder          bool
+	rawText            bool
+	includeHTML        bool
+	productConcurrency int
+	minDelay           time.Duration
+	maxDelay           time.Duration
+	connections        ConnectionOptions
+	headers            http.Header
+	cookies            []*http.Cookie
+	checkpointDir      string
+	resume             bool
+
+	robotsMu sync.Mutex
+	robots   *robotsRules
+}
+
+// Option configures a Scraper.
+type Option func(s *Scraper)
+
+// WithHTTPClient sets the HTTP client used for all requests to Trustpilot.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Scraper) {
+		s.client = client
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the delay before the first retry.
+// The delay is doubled for every next retry. Zero maxRetries disables retries.
+func WithRetries(maxRetries int, baseDelay time.Duration) Option {
+	return func(s *Scraper) {
+		s.maxRetries = maxRetries
+		s.retryBaseDelay = baseDelay
+	}
+}
+
+// WithMaxRetryAfter sets the longest delay the scraper agrees to wait when Trustpilot responds
+// with 429 Too Many Requests, so a huge Retry-After can't stall the scraping forever.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(s *Scraper) {
+		s.maxRetryAfter = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. By default, a desktop Chrome one is used,
+// as Trustpilot sometimes blocks Go's default User-Agent.
+func WithUserAgent(userAgent string) Option {
+	return func(s *Scraper) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithFilter adds a filter which decides whether the review is kept. Reviews are dropped as soon as they're scraped,
+// so the filtered out ones never reach the result. Every filter must pass for the review to be kept.
+func WithFilter(filter func(review *Review) bool) Option {
+	return func(s *Scraper) {
+		s.filters = append(s.filters, filter)
+	}
+}
+
+// WithDomain sets the Trustpilot host to scrape, like "uk.trustpilot.com" or "dk.trustpilot.com",
+// as Trustpilot serves different content per domain. Use ValidateDomain to check the value beforehand.
+func WithDomain(domain string) Option {
+	return func(s *Scraper) {
+		s.domain = domain
+	}
+}
+
+// ValidateDomain checks that the domain looks like a Trustpilot host.
+func ValidateDomain(domain string) error {
+	if !domainRe.MatchString(domain) {
+		return fmt.Errorf("domain %q doesn't look like a Trustpilot host, e.g. %s or uk.trustpilot.com", domain, DefaultDomain)
+	}
+
+	return nil
+}
+
+// WithSortOrder sets the order of the reviews returned by Reviews. By default, the newest reviews go first.
+func WithSortOrder(order SortOrder) Option {
+	return func(s *Scraper) {
+		s.sortOrder = order
+	}
+}
+
+// WithPageRange limits the scraping to the pages from start to end inclusive. Zero end means the last page.
+// The range is clamped to the actual number of pages. The first page is always requested to discover
+// the number of pages, but its reviews are skipped when start is greater than 1.
+func WithPageRange(start, end int) Option {
+	return func(s *Scraper) {
+		s.startPage = start
+		s.endPage = end
+	}
+}
+
+// WithMaxReviews stops the scraping once n reviews are collected, cancelling the pending page requests.
+// Filtered out reviews don't count. Zero n means no limit.
+func WithMaxReviews(n int) Option {
+	return func(s *Scraper) {
+		s.maxReviews = n
+	}
+}
+
+// WithNewerThan keeps only the reviews posted after the date and stops paginating once older reviews
+// are reached, which makes incremental scraping against a previous run cheap.
+func WithNewerThan(date time.Time) Option {
+	return func(s *Scraper) {
+		s.newerThan = date
+		s.filters = append(s.filters, func(review *Review) bool {
+			return review.ParsedDate.After(date)
+		})
+	}
+}
+
+// WithConcurrency sets how many pages are scraped in parallel. Values below 1 are treated as 1.
+func WithConcurrency(n int) Option {
+	return func(s *Scraper) {
+		if n < 1 {
+			n = 1
+		}
+
+		s.concurrency = n
+	}
+}
+
+// WithRateLimit limits the number of requests per second made by the scraper, including retries.
+// Zero or negative rps disables the limit.
+func WithRateLimit(rps float64) Option {
+	return func(s *Scraper) {
+		if rps <= 0 {
+			s.limiter = rate.NewLimiter(rate.Inf, 0)
+
+			return
+		}
+
+		s.limiter = rate.NewLimiter(rate.Limit(rps), 1)
+	}
+}
+
+// WithRateLimiter sets the limiter every request waits for. It lets several scrapers share one limit.
+func WithRateLimiter(limiter *rate.Limiter) Option {
+	return func(s *Scraper) {
+		s.limiter = limiter
+	}
+}
+
+// WithProxy routes all requests through the proxy, see ParseProxyURL for the supported schemes.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(s *Scraper) {
+		s.proxy = proxyURL
+	}
+}
+
+// WithIgnoreRobots disables the robots.txt check made before scraping a product.
+func WithIgnoreRobots(ignore bool) Option {
+	return func(s *Scraper) {
+		s.ignoreRobots = ignore
+	}
+}
+
+// WithPagination sets how the pages after the first one are discovered. By default, they are scraped in parallel.
+func WithPagination(pagination Pagination) Option {
+	return func(s *Scraper) {
+		s.pagination = pagination
+	}
+}
+
+// WithMaxPages caps the number of pages followed by the sequential pagination, so broken pagination
+// links can't make it loop forever.
+func WithMaxPages(n int) Option {
+	return func(s *Scraper) {
+		s.maxPages = n
+	}
+}
+
+// WithPageTimeout limits every page request attempt, including reading the body, independently of the client timeout.
+// Timed out attempts are retried like other network errors. Zero disables the limit.
+func WithPageTimeout(timeout time.Duration) Option {
+	return func(s *Scraper) {
+		s.pageTimeout = timeout
+	}
+}
+
+// WithDelay makes every page request wait for a random duration from minDelay to maxDelay before it's made,
+// so the requests don't come at the regular intervals of the rate limit. Zero durations disable the delay.
+func WithDelay(minDelay, maxDelay time.Duration) Option {
+	return func(s *Scraper) {
+		s.minDelay = minDelay
+		s.maxDelay = maxDelay
+	}
+}
+
+// WithLogger sets the logger of the scraper. By default, slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scraper) {
+		s.logger = logger
+	}
+}
+
+// WithProgress sets the receiver of the scraping progress, see NewTerminalProgress for a ready one.
+func WithProgress(progress Progress) Option {
+	return func(s *Scraper) {
+		s.progress = progress
+	}
+}
+
+// NewScraper creates a new Scraper. By default, it uses an HTTP client with a 30 seconds timeout,
+// makes up to 3 attempts for every request, scrapes 5 pages in parallel and makes at most 2 requests per second.
+func NewScraper(opts ...Option) *Scraper {
+	s := &Scraper{
+		client:         &http.Client{Timeout: defaultTimeout},
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+		maxRetryAfter:  defaultMaxRetryAfter,
+		userAgent:      defaultUserAgent,
+		domain:         DefaultDomain,
+		sortOrder:      SortDateDesc,
+		startPage:      1,
+		concurrency:    defaultConcurrency,
+		limiter:        rate.NewLimiter(defaultRPS, 1),
+		pagination:     PaginationParallel,
+		maxPages:       defaultMaxPages,
+		pageTimeout:    defaultPageTimeout,
+		logger:         slog.Default(),
+		progress:       noopProgress{},
+
+		challengeMarkers:   DefaultChallengeMarkers,
+		selectors:          DefaultSelectors,
+		productConcurrency: 1,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.client = configureTransport(s.client, func(transport *http.Transport) {
+		tuneTransport(transport, s.connections, s.concurrency)
+
+		if s.proxy != nil {
+			transport.Proxy = http.ProxyURL(s.proxy)
+		}
+	})
+
+	// cookiejar.New never fails without options, so the error is only logged
+	if len(s.cookies) > 0 {
+		if client, err := configureCookies(s.client, s.domain, s.cookies); err != nil {
+			s.logger.Error("Cannot configure cookies", "error", err)
+		} else {
+			s.client = client
+		}
+	}
+
+	// the cache wraps the configured transport, as custom round trippers can't be configured anymore
+	if s.cacheDir != "" {
+		s.cache = NewCacheTransport(s.cacheDir, s.cacheTTL, s.client.Transport).(*cacheTransport)
+		cached := *s.client
+		cached.Transport = s.cache
+		s.client = &cached
+	}
+
+	return s
+}
+
+// Reviews scrapes all review pages of the product and returns the collected reviews in the configured order.
+// If only some pages failed or ctx was cancelled, the reviews collected so far are returned together with the error,
+// see IsPartial.
+func (s *Scraper) Reviews(ctx context.Context, product string) (*ProductReviews, error) {
+	return s.CollectReviews(ctx, product, nil)
+}
+
+// CollectReviews is Reviews that also calls handle for every review as soon as it's scraped, e.g. to post the reviews
+// somewhere while the whole product is still collected. As with ReviewsFunc, handle is never called concurrently and
+// receives the reviews in the order of arrival, before the deduplication, and the resumed checkpoint reviews
+// aren't passed to it. If handle returns an error, the scraping stops and the error is returned without the reviews.
+func (s *Scraper) CollectReviews(ctx context.Context, product string, handle func(review *Review) error) (*ProductReviews, error) {
+	product, err := NormalizeProduct(product)
+	if err != nil {
+		return nil, err
+	}
+
+	var cp *checkpoint
+	if s.checkpointDir != "" {
+		if cp, err = s.openCheckpoint(product); err != nil {
+			return nil, err
+		}
+	}
+
+	var summary productSummary
+	reviews := make([]*Review, 0)
+	if cp != nil {
+		reviews = append(reviews, cp.Reviews...)
+		summary.filtered = cp.Filtered
+	}
+
+	err = s.getProductReviews(ctx, product, func(review *Review) error {
+		if handle != nil {
+			if err := handle(review); err != nil {
+				return err
+			}
+		}
+
+		reviews = append(reviews, review)
+
+		return nil
+	}, &summary, cp)
+
+	if err != nil && !IsPartial(err) {
+		return nil, err
+	}
+
+	// a partial scraping keeps the checkpoint for the next attempt to resume from
+	if cp != nil {
+		if cpErr := s.closeCheckpoint(cp, err); cpErr != nil {
+			s.logger.Error("Cannot update checkpoint", "product", product, "error", cpErr)
+		}
+	}
+
+	// dedup goes after all pages are collected, as the same review may come from overlapping pages or retries
+	reviews, duplicates := DeduplicateReviews(reviews)
+	if duplicates > 0 {
+		s.logger.Info("Dropped duplicate reviews", "product", product, "duplicates", duplicates)
+	}
+
+	// pages are scraped in parallel, so we sort the reviews to get the same order on every run
+	SortReviews(reviews, s.sortOrder)
+
+	productReviews := &ProductReviews{
+		ProductName: product,
+		Reviews:     reviews,
+		Stats:       ComputeStats(reviews),
+		Business:    summary.business,
+		Filtered:    summary.filtered,
+	}
+
+	var pagesErr *PagesError
+	if errors.As(err, &pagesErr) {
+		productReviews.FailedPages = pagesErr.Pages()
+	}
+
+	return productReviews, err
+}
+
+// ReviewsFunc scrapes all review pages of the product and calls handle for every review as soon as it's scraped,
+// so the reviews don't have to be kept in memory. handle is never called concurrently and receives the reviews
+// in the order of arrival, as the pages are scraped in parallel.
+// If handle returns an error, the rest of the reviews are skipped and the first error is returned.
+// If only some pages failed, a *PagesError is returned after all other reviews are handled.
+// Cancelling ctx stops the scraping promptly and returns the context error.
+func (s *Scraper) ReviewsFunc(ctx context.Context, product string, handle func(review *Review) error) error {
+	return s.getProductReviews(ctx, product, handle, nil, nil)
+}
+
+// ReviewsStream scrapes all review pages of the product in the background and emits every review on the returned
+// channel as soon as it's scraped. Both channels are closed once the scraping is done, and the error channel receives
+// at most one error, with the same semantics as the error of ReviewsFunc.
+// Callers must drain the reviews channel or cancel ctx, otherwise the scraping goroutines are leaked.
+func (s *Scraper) ReviewsStream(ctx context.Context, product string) (<-chan *Review, <-chan error) {
+	reviews := make(chan *Review)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(reviews)
+
+		err := s.ReviewsFunc(ctx, product, func(review *Review) error {
+			select {
+			case reviews <- review:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return reviews, errs
+}
+
+// productSummary is what the scraping learns about the product besides its reviews.
+type productSummary struct {
+	business Business
+	// filtered is the number of the scraped reviews dropped by the filters
+	filtered int
+}
+
+// getProductReviews scrapes the product reviews into handle. The business information of the first page
+// and the number of filtered reviews are added to summary unless it's nil. The pages of the checkpoint
+// are skipped, and the scraped ones are recorded into it unless it's nil.
+func (s *Scraper) getProductReviews(
+	ctx context.Context,
+	name string,
+	handle func(review *Review) error,
+	summary *productSummary,
+	cp *checkpoint,
+) error {
+	name, err := NormalizeProduct(name)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug("Start scraping page", "product", name, "page", 1)
+	s.progress.PageStarted(1)
+
+	doc, productURL, err := s.fetchProductPage(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if summary != nil {
+		summary.business = parseBusiness(doc)
+	}
+
+	firstPageReviews := s.parsePage(doc, productURL)
+	s.progress.PageDone(1, len(firstPageReviews))
+
+	// we need to find the pagination links and extract the number of pages for the product
+	lastPage, method := detectLastPage(doc)
+	if method != "" {
+		s.logger.Debug("Detected number of pages", "product", name, "pages", lastPage, "method", method)
+	}
+
+	// without pagination only the first page exists, so it must have reviews, otherwise the selectors are likely broken
+	if _, hasNext := nextPageURL(doc, productURL); lastPage <= 1 && !hasNext {
+		s.logger.Info("Single page detected", "product", name)
+
+		// a business without reviews shows a placeholder, which tells it apart from a broken page
+		if len(firstPageReviews) == 0 && hasEmptyState(doc) {
+			s.logger.Info("Product has no reviews yet", "product", name)
+			s.progress.Finished(0)
+
+			return nil
+		}
+
+		if len(firstPageReviews) == 0 {
+			return fmt.Errorf("%w on the single page of %s, the page layout may have changed", ErrNoReviews, name)
+		}
+	}
+
+	if reporter, ok := s.progress.(PagesReporter); ok && s.pagination == PaginationParallel {
+		reporter.PagesFound(s.pagesToScrape(lastPage, cp))
+	}
+
+	// the pending page requests are cancelled once we have enough reviews
+	scrapeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// we synchronize reviews processing with a channel, as we scrape reviews from multiple pages in parallel
+	pagesChan := make(chan pageBatch)
+	quitChan := make(chan struct{})
+
+	// we handle reviews in a separate goroutine from pagesChan
+	var (
+		handleErr error
+		handled   int
+		filtered  int
+	)
+	go func() {
+		for batch := range pagesChan {
+			complete := true
+			for _, review := range batch.reviews {
+				// keep draining the channel after a failure or reaching the limit, so the producers are not blocked
+				if handleErr != nil || (s.maxReviews > 0 && handled >= s.maxReviews) {
+					complete = false
+
+					continue
+				}
+
+				// the language is detected before the filters, so they can rely on it
+				if s.detectLanguage {
+					review.Language = detectLanguage(review)
+				}
+
+				if !s.keep(review) {
+					filtered++
+					cp.drop()
+
+					continue
+				}
+
+				handleErr = handle(review)
+				handled++
+				s.metrics.reviewCollected()
+				if handleErr == nil {
+					cp.add(review)
+				} else {
+					// the rest of the reviews would be skipped anyway, so the pending page requests are cancelled
+					cancel()
+				}
+
+				if s.maxReviews > 0 && handled >= s.maxReviews {
+					s.logger.Info("Collected enough reviews, stopping", "product", name, "reviews", handled)
+					cancel()
+				}
+			}
+
+			if complete && handleErr == nil {
+				if err := cp.pageDone(batch.page); err != nil {
+					s.logger.Error("Cannot save checkpoint", "product", name, "error", err)
+				}
+			}
+		}
+
+		close(quitChan)
+	}()
+
+	// the collector goroutine must be stopped on every return path, including panics of the page scraping,
+	// so it's never left blocked on the channel
+	stopCollecting := sync.OnceFunc(func() {
+		close(pagesChan)
+		<-quitChan
+	})
+	defer stopCollecting()
+
+	cutoff := newPageCutoff(s.newerThan)
+
+	// to avoid one extra request, we process first page here separately
+	if s.startPage <= 1 {
+		cutoff.check(1, firstPageReviews)
+
+		if !cp.skips(1) {
+			pagesChan <- pageBatch{page: 1, reviews: firstPageReviews}
+		}
+	}
+
+	// pages are scraped in parallel, so the failures are collected under a mutex
+	var pageErrsMu sync.Mutex
+	pageErrs := make(map[int]error)
+	onPageErr := func(page int, err error) {
+		// the pages cancelled because of the reviews limit or the caller are not failures of the pages
+		if scrapeCtx.Err() != nil {
+			return
+		}
+
+		pageErrsMu.Lock()
+		defer pageErrsMu.Unlock()
+
+		pageErrs[page] = err
+	}
+
+	if s.pagination == PaginationSequential {
+		s.scrapePagesSequentially(scrapeCtx, pagesChan, name, productURL, doc, cutoff, cp, onPageErr)
+	} else {
+		s.scrapePages(scrapeCtx, pagesChan, name, productURL, lastPage, cutoff, cp, onPageErr)
+	}
+
+	// wait until all reviews are handled
+	stopCollecting()
+
+	if summary != nil {
+		summary.filtered += filtered
+	}
+
+	s.progress.Finished(handled)
+
+	if handleErr != nil {
+		return handleErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(pageErrs) > 0 {
+		return &PagesError{Errors: pageErrs}
+	}
+
+	return nil
+}
+
+// pagesToScrape counts the pages requested by the parallel pagination: the first page, which is always requested,
+// and the pages of the range up to the last page, except the ones of the checkpoint.
+func (s *Scraper) pagesToScrape(lastPage int, cp *checkpoint) int {
+	if s.endPage > 0 && s.endPage < lastPage {
+		lastPage = s.endPage
+	}
+
+	pages := 1
+	for page := max(s.startPage, 2); page <= lastPage; page++ {
+		if !cp.skips(page) {
+			pages++
+		}
+	}
+
+	return pages
+}
+
+// keep reports whether the review passes all filters.
+func (s *Scraper) keep(review *Review) bool {
+	for _, filter := range s.filters {
+		if !filter(review) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fetchProductPage checks robots.txt and requests the first review page of the product. It returns the URL
+// of the product page after the redirects, which is the base of the review links and the pagination.
+func (s *Scraper) fetchProductPage(ctx context.Context, name string) (*goquery.Document, string, error) {
+	productURL := fmt.Sprintf(scrapingURL, s.domain, name)
+	if !s.ignoreRobots {
+		if err := s.checkRobots(ctx, "/review/"+name); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// make a request to the product page and transform the HTML document into a goquery document
+	// which will allow us to use a jquery-like syntax
+	doc, err := s.fetchDocument(ctx, productURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Trustpilot redirects renamed products to their canonical slug, so we continue from there
+	if doc.Url != nil {
+		finalURL := *doc.Url
+		finalURL.RawQuery, finalURL.Fragment = "", ""
+		if resolved := finalURL.String(); resolved != productURL {
+			s.logger.Info("Product page redirected", "product", name, "from", productURL, "to", resolved)
+			productURL = resolved
+		}
+	}
+
+	return doc, productURL, nil
+}
+
+// detectLastPage finds the number of pages of the product. It prefers the link to the last page and falls back
+// to the greatest of the page number links, as the last page link is missing on some layouts.
+// It also returns which method found the number, empty when there is no pagination at all.
+func detectLastPage(doc *goquery.Document) (int, string) {
+	if page, ok := pageFromHref(doc.Find("a[name='pagination-button-last']").First().AttrOr("href", "")); ok {
+		return page, "last page link"
+	}
+
+	lastPage := 0
+	doc.Find("a[name^='pagination-button-page']").Each(func(i int, link *goquery.Selection) {
+		if page, ok := pageFromHref(link.AttrOr("href", "")); ok && page > lastPage {
+			lastPage = page
+		}
+	})
+
+	if lastPage > 0 {
+		return lastPage, "page number links"
+	}
+
+	return 1, ""
+}
+
+// pageFromHref extracts the page number from the page query param of the pagination link.
+func pageFromHref(href string) (int, bool) {
+	match := pageParamRe.FindStringSubmatch(href)
+	if match == nil {
+		return 0, false
+	}
+
+	page, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+
+	return page, true
+}
+
+// logPageErr logs the failed page request. The requests in flight fail as well once the scraping is cancelled
+// by the reviews limit or the caller, which is not a failure of the page, so they're logged only at debug level.
+func (s *Scraper) logPageErr(ctx context.Context, name string, page int, err error) {
+	if ctx.Err() != nil {
+		s.logger.Debug("Page request cancelled", "product", name, "page", page, "error", err)
+
+		return
+	}
+
+	s.logger.Error("Cannot get page product reviews", "product", name, "page", page, "error", err)
+}
+
+// scrapePages scrapes the pages after the first one up to the last page and sends their reviews to the channel.
+// The pages of the checkpoint are not requested.
+func (s *Scraper) scrapePages(
+	ctx context.Context,
+	pages chan<- pageBatch,
+	name string,
+	productURL string,
+	lastPage int,
+	cutoff *pageCutoff,
+	cp *checkpoint,
+	onPageErr func(page int, err error),
+) {
+	// the first page is already processed, and the requested range can't go beyond the last page
+	firstPage := 2
+	if s.startPage > firstPage {
+		firstPage = s.startPage
+	}
+
+	if firstPage > lastPage && s.startPage > 1 {
+		s.logger.Warn("Start page is beyond the last page", "product", name, "start_page", s.startPage, "last_page", lastPage)
+	}
+
+	if s.endPage > 0 && s.endPage < lastPage {
+		lastPage = s.endPage
+	}
+
+	// the reviews go straight to the channel unless the page order is required
+	emit := func(page int, batch *pageBatch) {
+		if batch != nil {
+			pages <- *batch
+		}
+	}
+	if s.pageOrder {
+		orderer := newPageOrderer(firstPage, pages)
+		emit = orderer.done
+		defer orderer.flush()
+	}
+
+	// scrape pages in parallel with a bounded number of workers, so we don't open a connection per page
+	jobs := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < s.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for pageNumber := range jobs {
+				// drain the remaining jobs without requests once the scraping is cancelled
+				if ctx.Err() != nil {
+					emit(pageNumber, nil)
+
+					continue
+				}
+
+				pageReviews, err := s.getPageProductReviews(ctx, name, productURL, pageNumber)
+				// an earlier page may reach the cutoff meanwhile, then this page is not needed anymore
+				if cutoff.beyond(pageNumber) {
+					emit(pageNumber, nil)
+
+					continue
+				}
+
+				if err != nil {
+					s.logPageErr(ctx, name, pageNumber, err)
+					onPageErr(pageNumber, err)
+					emit(pageNumber, nil)
+
+					continue
+				}
+
+				cutoff.check(pageNumber, pageReviews)
+				emit(pageNumber, &pageBatch{page: pageNumber, reviews: pageReviews})
+			}
+		}()
+	}
+
+pages:
+	for i := firstPage; i <= lastPage; i++ {
+		if cutoff.beyond(i) {
+			s.logger.Info("Reached already scraped reviews, stopping", "product", name, "page", i-1)
+
+			break
+		}
+
+		if cp.skips(i) {
+			emit(i, nil)
+
+			continue
+		}
+
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break pages
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+}
+
+// getPageProductReviews scrapes the page of the product. productURL is used to construct the page URL
+// and the links to the reviews. It's pure, without query params.
+func (s *Scraper) getPageProductReviews(ctx context.Context, name, productURL string, page int) ([]*Review, error) {
+	s.logger.Debug("Start scraping page", "product", name, "page", page)
+	s.progress.PageStarted(page)
+
+	// actual request URL for scraping a page
+	productRequestURL := fmt.Sprintf(scrapingPageURL, productURL, page)
+	doc, err := s.fetchDocument(ctx, productRequestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := s.parsePage(doc, productURL)
+	s.progress.PageDone(page, len(reviews))
+
+	return reviews, nil
+}
+
+// fetchDocument requests the page and parses it into a goquery document. A bot challenge, a response other than HTML
+// or a document without recognizable Trustpilot markup is an error, so an error page or a truncated body isn't reported
+// as a page without reviews.
+func (s *Scraper) fetchDocument(ctx context.Context, pageURL string) (*goquery.Document, error) {
+	if delay := s.pageDelay(); delay > 0 {
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+
+	// goquery parses the body as it's read, so the raw page is never buffered next to the parsed document
+	var (
+		doc *goquery.Document
+		req *http.Request
+	)
+	err := s.fetchBody(ctx, pageURL, func(res *http.Response) error {
+		req = res.Request
+		if err := checkContentType(res, pageURL); err != nil {
+			s.cache.evict(req)
+
+			return err
+		}
+
+		var err error
+		doc, err = goquery.NewDocumentFromReader(res.Body)
+		if err != nil {
+			return fmt.Errorf("parse page %s: %w", pageURL, err)
+		}
+
+		// the final URL after the redirects is the base of the links of the page
+		doc.Url = res.Request.URL
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.pageFetched()
+
+	// the cache stores every successful response, so the pages which turn out to be unusable are dropped from it,
+	// otherwise the next runs would replay them
+	if err := s.checkChallengeDocument(doc, pageURL); err != nil {
+		s.cache.evict(req)
+
+		return nil, err
+	}
+
+	if err := checkMarkup(doc, pageURL); err != nil {
+		s.cache.evict(req)
+
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// parsePageReviews extracts all reviews from the page document. The embedded __NEXT_DATA__ JSON is the primary source,
+// and the markup of the review cards is the fallback when the JSON is missing or has no reviews. The images are always
+// taken from the markup, as the JSON has none.
+func parsePageReviews(doc *goquery.Document, productURL string, sel *Selectors, includeHTML bool) []*Review {
+	if reviews, ok := parseNextDataReviews(doc, productURL); ok && len(reviews) > 0 {
+		addCardImages(doc, reviews, productURL, sel)
+
+		return reviews
+	}
+
+	reviews := make([]*Review, 0)
+	reviewsChan := make(chan *Review)
+	quitChan := make(chan struct{})
+
+	go func() {
+		for review := range reviewsChan {
+			reviews = append(reviews, review)
+		}
+
+		close(quitChan)
+	}()
+
+	// extract reviews from the page. We visit only the review cards, and scan every div only when the cards
+	// can't be found, e.g. when their classes got another prefix
+	cards := doc.Find(sel.Card)
+	if cards.Length() == 0 {
+		cards = doc.Find("div")
+	}
+	cards.Each(extractReviewFunc(reviewsChan, productURL, sel, includeHTML))
+
+	close(reviewsChan)
+	<-quitChan
+
+	return reviews
+}
@@ -0,0 +1,284 @@
+package trustpilot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+	"golang.org/x/time/rate"
+)
+
+const testProduct = "example.com"
+
+// testSite serves the review pages of testProduct, every page with one review card.
+type testSite struct {
+	server *httptest.Server
+	// pages is the number of review pages, 0 serves a page without review cards
+	pages int
+	// fixture is the testdata file served instead of the generated pages
+	fixture string
+	// hits counts the page requests
+	hits atomic.Int32
+	// challenges is the number of the next requests answered with a bot challenge page
+	challenges atomic.Int32
+	// hang makes the requests of the pages after the first one wait until the client gives up
+	hang bool
+}
+
+func newTestSite(t *testing.T, pages int) *testSite {
+	t.Helper()
+
+	site := &testSite{pages: pages}
+	site.server = httptest.NewUnstartedServer(http.HandlerFunc(site.serve))
+	// the handshakes of the connections cancelled by the tests fail, which is expected
+	site.server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	site.server.StartTLS()
+	t.Cleanup(site.server.Close)
+
+	return site
+}
+
+func (site *testSite) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/review/"+testProduct {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	site.hits.Add(1)
+
+	page := 1
+	if value := r.URL.Query().Get("page"); value != "" {
+		page, _ = strconv.Atoi(value)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if site.challenges.Add(-1) >= 0 {
+		fmt.Fprint(w, `<html><head><title>Just a moment...</title></head><body></body></html>`)
+
+		return
+	}
+	if site.hang && page > 1 {
+		<-r.Context().Done()
+
+		return
+	}
+
+	if site.fixture != "" {
+		http.ServeFile(w, r, filepath.Join("testdata", site.fixture))
+
+		return
+	}
+	fmt.Fprint(w, site.page(page))
+}
+
+func (site *testSite) page(page int) string {
+	var body strings.Builder
+	body.WriteString(`<html><head><title>Reviews</title></head><body><div class="styles_businessUnitHeader__x"></div>`)
+
+	if site.pages == 0 {
+		body.WriteString(`</body></html>`)
+
+		return body.String()
+	}
+
+	fmt.Fprintf(&body, `<div class="styles_reviewCard__x styles_cardWrapper__x">
+<div data-service-review-rating="4"><img alt="Rated 4 out of 5 stars"></div>
+<time datetime="2024-03-%02dT10:00:00.000Z"></time>
+<a data-review-title-typography href="/reviews/page%d"><h2>Review of page %d</h2></a>
+<p data-service-review-text-typography>Text of page %d</p>
+<span data-consumer-name-typography>Reviewer %d</span>
+</div>`, page%28+1, page, page, page, page)
+
+	if site.pages > 1 {
+		fmt.Fprintf(&body, `<nav><a name="pagination-button-last" href="/review/%s?page=%d">Last</a>`, testProduct, site.pages)
+		if page < site.pages {
+			fmt.Fprintf(&body, `<a name="pagination-button-next" href="/review/%s?page=%d">Next</a>`, testProduct, page+1)
+		}
+		body.WriteString(`</nav>`)
+	}
+
+	body.WriteString(`</body></html>`)
+
+	return body.String()
+}
+
+// scraper returns a scraper of the site without rate limits, retries and logs.
+func (site *testSite) scraper(opts ...Option) *Scraper {
+	opts = append([]Option{
+		WithHTTPClient(site.server.Client()),
+		WithDomain(strings.TrimPrefix(site.server.URL, "https://")),
+		WithIgnoreRobots(true),
+		WithRateLimiter(rate.NewLimiter(rate.Inf, 0)),
+		WithRetries(0, 0),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, opts...)
+
+	return NewScraper(opts...)
+}
+
+func TestReviewsFuncStopsOnHandleError(t *testing.T) {
+	const concurrency = 2
+
+	site := newTestSite(t, 20)
+	scraper := site.scraper(WithConcurrency(concurrency))
+
+	errHandle := errors.New("handle failed")
+	err := scraper.ReviewsFunc(context.Background(), testProduct, func(*Review) error {
+		return errHandle
+	})
+	if !errors.Is(err, errHandle) {
+		t.Fatalf("got error %v, want %v", err, errHandle)
+	}
+
+	// the first page plus the requests in flight when the handler failed
+	if hits := site.hits.Load(); hits > 1+concurrency {
+		t.Errorf("requested %d of %d pages after the handler failed on the first review", hits, site.pages)
+	}
+}
+
+func TestReviewsFuncDoesNotLeakGoroutines(t *testing.T) {
+	errHandle := errors.New("handle failed")
+
+	tests := []struct {
+		name    string
+		handle  func(cancel context.CancelFunc, handled int) error
+		wantErr error
+	}{
+		{
+			name:   "complete",
+			handle: func(context.CancelFunc, int) error { return nil },
+		},
+		{
+			name: "handler fails",
+			handle: func(_ context.CancelFunc, handled int) error {
+				if handled == 3 {
+					return errHandle
+				}
+
+				return nil
+			},
+			wantErr: errHandle,
+		},
+		{
+			name: "cancelled mid-scrape",
+			handle: func(cancel context.CancelFunc, handled int) error {
+				if handled == 3 {
+					cancel()
+				}
+
+				return nil
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := newTestSite(t, 20)
+			// the test server and the goroutines of the other tests are not ours
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+			scraper := site.scraper(WithConcurrency(4))
+			// the idle keep-alive connections keep their goroutines until they're closed
+			defer scraper.client.CloseIdleConnections()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			handled := 0
+			err := scraper.ReviewsFunc(ctx, testProduct, func(*Review) error {
+				handled++
+
+				return tt.handle(cancel, handled)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReviewsSinglePage(t *testing.T) {
+	t.Run("one page", func(t *testing.T) {
+		site := newTestSite(t, 1)
+
+		productReviews, err := site.scraper().Reviews(context.Background(), testProduct)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(productReviews.Reviews) != 1 || productReviews.Reviews[0].Title != "Review of page 1" {
+			t.Errorf("got reviews %+v, want the review of page 1", productReviews.Reviews)
+		}
+
+		if hits := site.hits.Load(); hits != 1 {
+			t.Errorf("requested %d pages, want only the first one", hits)
+		}
+	})
+
+	t.Run("no reviews yet", func(t *testing.T) {
+		site := newTestSite(t, 0)
+		site.fixture = "empty_state.html"
+
+		// the placeholder tells a business without reviews apart from a broken page
+		productReviews, err := site.scraper().Reviews(context.Background(), testProduct)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(productReviews.Reviews) != 0 {
+			t.Errorf("got %d reviews, want none", len(productReviews.Reviews))
+		}
+	})
+
+	t.Run("no reviews", func(t *testing.T) {
+		site := newTestSite(t, 0)
+
+		// without pagination and the placeholder of a business without reviews, the selectors are likely broken
+		_, err := site.scraper().Reviews(context.Background(), testProduct)
+		if !errors.Is(err, ErrNoReviews) {
+			t.Fatalf("got error %v, want %v", err, ErrNoReviews)
+		}
+	})
+}
+
+func TestCancelledPagesAreNotLoggedAsErrors(t *testing.T) {
+	site := newTestSite(t, 20)
+	site.hang = true
+
+	var logs bytes.Buffer
+	scraper := site.scraper(WithConcurrency(4), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// we cancel once the requests of the next pages are in flight
+	go func() {
+		for site.hits.Load() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	if _, err := scraper.Reviews(ctx, testProduct); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+
+	if strings.Contains(logs.String(), "level=ERROR") {
+		t.Errorf("cancelled page requests are logged as errors:\n%s", logs.String())
+	}
+}
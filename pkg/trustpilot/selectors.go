@@ -0,0 +1,62 @@
+package trustpilot
+
+// Selectors are the goquery selectors used to extract the reviews from the markup of the review cards.
+// Trustpilot changes its markup often, so they can be patched without waiting for a new release.
+// They are used only when the page has no __NEXT_DATA__ JSON.
+type Selectors struct {
+	// Card matches the review card containers on the page.
+	Card string `json:"card" yaml:"card"`
+	// Text, Title, Date, Rating and Author match the elements inside the card.
+	Text   string `json:"text" yaml:"text"`
+	Title  string `json:"title" yaml:"title"`
+	Date   string `json:"date" yaml:"date"`
+	Rating string `json:"rating" yaml:"rating"`
+	Author string `json:"author" yaml:"author"`
+	// Reply matches the text of the business reply inside the card.
+	Reply string `json:"reply" yaml:"reply"`
+	// Images matches the images uploaded by the reviewer inside the card.
+	Images string `json:"images" yaml:"images"`
+}
+
+// DefaultSelectors match the current Trustpilot markup.
+var DefaultSelectors = Selectors{
+	Card:   "div[class*='styles_reviewCard__'][class*='styles_cardWrapper__']",
+	Text:   "p[data-service-review-text-typography]",
+	Title:  "h2",
+	Date:   "time",
+	Rating: "[data-service-review-rating]",
+	Author: "span[data-consumer-name-typography]",
+	Reply:  "p[data-service-review-business-reply-text-typography]",
+	Images: "[data-review-images] img, [class*='styles_reviewImages__'] img",
+}
+
+// WithSelectors replaces the selectors of the review card markup. Empty selectors keep their default values.
+func WithSelectors(selectors Selectors) Option {
+	return func(s *Scraper) {
+		s.selectors = selectors.withDefaults()
+	}
+}
+
+// withDefaults fills the empty selectors with the default ones.
+func (sel Selectors) withDefaults() Selectors {
+	defaults := []struct {
+		value    *string
+		fallback string
+	}{
+		{&sel.Card, DefaultSelectors.Card},
+		{&sel.Text, DefaultSelectors.Text},
+		{&sel.Title, DefaultSelectors.Title},
+		{&sel.Date, DefaultSelectors.Date},
+		{&sel.Rating, DefaultSelectors.Rating},
+		{&sel.Author, DefaultSelectors.Author},
+		{&sel.Reply, DefaultSelectors.Reply},
+		{&sel.Images, DefaultSelectors.Images},
+	}
+	for _, d := range defaults {
+		if *d.value == "" {
+			*d.value = d.fallback
+		}
+	}
+
+	return sel
+}
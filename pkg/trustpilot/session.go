@@ -0,0 +1,38 @@
+package trustpilot
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+// WithHeaders adds the headers to every request to the Trustpilot domain, e.g. to pin the region or to pass
+// a session token. They take precedence over the User-Agent set by WithUserAgent.
+func WithHeaders(headers http.Header) Option {
+	return func(s *Scraper) {
+		s.headers = headers.Clone()
+	}
+}
+
+// WithCookies sends the cookies with every request to the Trustpilot domain, e.g. the cookies of a logged-in session.
+// The cookies set by Trustpilot in the responses are kept for the next requests as well.
+func WithCookies(cookies []*http.Cookie) Option {
+	return func(s *Scraper) {
+		s.cookies = cookies
+	}
+}
+
+// configureCookies returns a copy of the client with a cookie jar holding the cookies for the domain.
+func configureCookies(client *http.Client, domain string, cookies []*http.Cookie) (*http.Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	jar.SetCookies(&url.URL{Scheme: "https", Host: domain, Path: "/"}, cookies)
+
+	configured := *client
+	configured.Jar = jar
+
+	return &configured, nil
+}
@@ -0,0 +1,72 @@
+package trustpilot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortOrder defines the order of the collected reviews.
+type SortOrder string
+
+const (
+	// SortDateDesc puts the newest reviews first.
+	SortDateDesc SortOrder = "date-desc"
+	// SortDateAsc puts the oldest reviews first.
+	SortDateAsc SortOrder = "date-asc"
+	// SortRating puts the highest rated reviews first.
+	SortRating SortOrder = "rating"
+	// SortUsefulDesc puts the reviews found useful by the most people first.
+	SortUsefulDesc SortOrder = "useful-desc"
+	// SortPage keeps the order of collection, which follows the pages with WithPageOrder.
+	SortPage SortOrder = "page"
+)
+
+// ParseSortOrder checks that the order is one of the supported ones.
+func ParseSortOrder(order string) (SortOrder, error) {
+	switch SortOrder(order) {
+	case SortDateDesc, SortDateAsc, SortRating, SortUsefulDesc, SortPage:
+		return SortOrder(order), nil
+	default:
+		return "", fmt.Errorf("unsupported sort order %q, expected one of: %s, %s, %s, %s, %s",
+			order, SortDateDesc, SortDateAsc, SortRating, SortUsefulDesc, SortPage)
+	}
+}
+
+// SortReviews sorts the reviews in place. Ties are broken by the review ID, so the order is stable across runs.
+// SortPage leaves the reviews as they are.
+func SortReviews(reviews []*Review, order SortOrder) {
+	if order == SortPage {
+		return
+	}
+
+	less := func(a, b *Review) bool {
+		return a.ParsedDate.After(b.ParsedDate)
+	}
+
+	switch order {
+	case SortDateAsc:
+		less = func(a, b *Review) bool {
+			return a.ParsedDate.Before(b.ParsedDate)
+		}
+	case SortRating:
+		less = func(a, b *Review) bool {
+			return a.Stars > b.Stars
+		}
+	case SortUsefulDesc:
+		less = func(a, b *Review) bool {
+			return a.Useful > b.Useful
+		}
+	}
+
+	sort.SliceStable(reviews, func(i, j int) bool {
+		if less(reviews[i], reviews[j]) {
+			return true
+		}
+
+		if less(reviews[j], reviews[i]) {
+			return false
+		}
+
+		return reviews[i].ID < reviews[j].ID
+	})
+}
@@ -0,0 +1,13 @@
+package trustpilot
+
+import "context"
+
+// ReviewSource is a site the product reviews are scraped from. Review and ProductReviews don't depend
+// on Trustpilot, so other review sites can implement it and share the output code.
+type ReviewSource interface {
+	// Reviews returns all collected reviews of the product. Like Scraper.Reviews, it may return the reviews
+	// together with an error when the result is partial, see IsPartial.
+	Reviews(ctx context.Context, product string) (*ProductReviews, error)
+}
+
+var _ ReviewSource = (*Scraper)(nil)
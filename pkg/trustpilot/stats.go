@@ -0,0 +1,36 @@
+package trustpilot
+
+// Stats summarizes the ratings of the reviews.
+type Stats struct {
+	Total int `json:"total"`
+	// Average is the mean number of stars over the reviews with a known rating.
+	Average float64 `json:"average"`
+	// Distribution maps the number of stars to the number of reviews with such rating.
+	Distribution map[int]int `json:"distribution"`
+}
+
+// ComputeStats computes the rating statistics of the reviews. Reviews with an unknown rating
+// count towards Total only.
+func ComputeStats(reviews []*Review) *Stats {
+	stats := &Stats{
+		Total:        len(reviews),
+		Distribution: make(map[int]int),
+	}
+
+	rated, sum := 0, 0
+	for _, review := range reviews {
+		if review.Stars == 0 {
+			continue
+		}
+
+		stats.Distribution[review.Stars]++
+		rated++
+		sum += review.Stars
+	}
+
+	if rated > 0 {
+		stats.Average = float64(sum) / float64(rated)
+	}
+
+	return stats
+}
@@ -0,0 +1,73 @@
+package trustpilot
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// WithTransform sets the function applied to every scraped review before the filters, e.g. to redact
+// personal data or to enrich the review. Returning nil drops the review.
+// It's called concurrently from the goroutines scraping the pages, so it must be safe for concurrent use.
+func WithTransform(transform func(review *Review) *Review) Option {
+	return func(s *Scraper) {
+		s.transform = transform
+	}
+}
+
+// WithRawText keeps the text, title and author of the reviews exactly as they're in the markup. By default,
+// they're trimmed and the runs of whitespace, including the line breaks of the markup, are collapsed into single spaces.
+func WithRawText(raw bool) Option {
+	return func(s *Scraper) {
+		s.rawText = raw
+	}
+}
+
+// WithRawHTML keeps the markup of the review card in Review.RawHTML, to find out why a field came out empty
+// without requesting the page again. The markup is large, so it's off by default.
+func WithRawHTML(include bool) Option {
+	return func(s *Scraper) {
+		s.includeHTML = include
+	}
+}
+
+// parsePage extracts the reviews of the page document, normalizes their text and applies the transform.
+func (s *Scraper) parsePage(doc *goquery.Document, productURL string) []*Review {
+	return s.transformReviews(s.normalizeReviews(parsePageReviews(doc, productURL, &s.selectors, s.includeHTML)))
+}
+
+// normalizeReviews normalizes the text fields of the reviews in place, unless the raw text is requested.
+func (s *Scraper) normalizeReviews(reviews []*Review) []*Review {
+	if s.rawText {
+		return reviews
+	}
+
+	for _, review := range reviews {
+		review.Text = normalizeText(review.Text)
+		review.Title = normalizeText(review.Title)
+		review.Author = normalizeText(review.Author)
+	}
+
+	return reviews
+}
+
+// normalizeText trims the text and collapses every run of whitespace into a single space.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// transformReviews applies the transform to the reviews of the page, dropping the ones it returns nil for.
+func (s *Scraper) transformReviews(reviews []*Review) []*Review {
+	if s.transform == nil {
+		return reviews
+	}
+
+	transformed := reviews[:0]
+	for _, review := range reviews {
+		if review = s.transform(review); review != nil {
+			transformed = append(transformed, review)
+		}
+	}
+
+	return transformed
+}
@@ -0,0 +1,111 @@
+package trustpilot
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultDialTimeout     = 30 * time.Second
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+// ConnectionOptions tune how the connections to Trustpilot are reused. Zero fields keep the defaults.
+//
+// Every page worker of WithConcurrency holds a connection while its request is in flight, and all of them go
+// to the same host, so by default MaxIdleConnsPerHost and MaxIdleConns are raised to the concurrency.
+// Otherwise most connections would be closed after every page and opened again for the next one.
+type ConnectionOptions struct {
+	// MaxIdleConns limits the idle connections to all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the idle connections kept to Trustpilot between the pages.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes the connections idle for longer, 90 seconds by default.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval of the TCP keep-alive probes, 30 seconds by default.
+	KeepAlive time.Duration
+}
+
+// WithConnectionOptions tunes the reuse of the connections, see ConnectionOptions.
+// It has no effect on a client with a custom round tripper.
+func WithConnectionOptions(opts ConnectionOptions) Option {
+	return func(s *Scraper) {
+		s.connections = opts
+	}
+}
+
+// tuneTransport applies the connection options to the transport. HTTP/2 is kept enabled, as Go disables it
+// on the transports with a custom dialer otherwise.
+func tuneTransport(transport *http.Transport, opts ConnectionOptions, concurrency int) {
+	transport.ForceAttemptHTTP2 = true
+
+	maxIdleConnsPerHost := opts.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost == 0 {
+		maxIdleConnsPerHost = max(concurrency, transport.MaxIdleConnsPerHost)
+	}
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	if opts.MaxIdleConns > 0 {
+		transport.MaxIdleConns = opts.MaxIdleConns
+	} else if transport.MaxIdleConns != 0 && transport.MaxIdleConns < maxIdleConnsPerHost {
+		transport.MaxIdleConns = maxIdleConnsPerHost
+	}
+
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	} else if transport.IdleConnTimeout == 0 {
+		transport.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
+	if opts.KeepAlive != 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: opts.KeepAlive,
+		}).DialContext
+	}
+}
+
+// ParseProxyURL parses and validates the proxy URL. The http, https and socks5 schemes are supported.
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", rawURL, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q, expected http, https or socks5", rawURL, proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", rawURL)
+	}
+
+	return proxyURL, nil
+}
+
+// configureTransport returns a copy of the client whose transport is adjusted by configure.
+// The transport of the client is cloned, so the original client stays untouched.
+func configureTransport(client *http.Client, configure func(transport *http.Transport)) *http.Client {
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		// custom round trippers are left as is, as we can't configure them
+		return client
+	}
+
+	configure(transport)
+
+	configured := *client
+	configured.Transport = transport
+
+	return &configured
+}